@@ -0,0 +1,69 @@
+package chaos
+
+import (
+	"fmt"
+	"time"
+
+	chaosv1alpha1 "github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+
+	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+)
+
+// DelayOpt is for configuring delay.
+type DelayOpt func(*delayOptions)
+
+type delayOptions struct {
+	target *Selector
+}
+
+// DelayTarget limits delay to the traffic between selected pods and pods chosen by target,
+// in both directions. By default all traffic of the selected pods is delayed.
+func DelayTarget(target Selector) DelayOpt {
+	return func(o *delayOptions) {
+		o.target = &target
+	}
+}
+
+// Delay adds latency with jitter to the network traffic of the pods.
+func Delay(cctx *testcontext.Context, name string, latency, jitter time.Duration, pods ...string) (error, Teardown) {
+	return DelaySelected(cctx, name, latency, jitter, Pods(pods...))
+}
+
+// DelaySelected adds latency with jitter to the network traffic of the pods chosen by selector.
+func DelaySelected(cctx *testcontext.Context, name string, latency, jitter time.Duration,
+	selector Selector, opts ...DelayOpt) (error, Teardown) {
+	var o delayOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := selector.validate(); err != nil {
+		return fmt.Errorf("delay %s: %w", name, err), nil
+	}
+	if o.target != nil {
+		if err := o.target.validate(); err != nil {
+			return fmt.Errorf("delay %s target: %w", name, err), nil
+		}
+	}
+	delay := chaosv1alpha1.NetworkChaos{}
+	delay.Name = name
+	delay.Namespace = cctx.Namespace
+
+	delay.Spec.Action = chaosv1alpha1.DelayAction
+	delay.Spec.PodSelector = selector.podSelector(cctx.Namespace)
+	delay.Spec.Delay = &chaosv1alpha1.DelaySpec{
+		Latency: latency.String(),
+		Jitter:  jitter.String(),
+	}
+	pods := selector.String()
+	if o.target != nil {
+		delay.Spec.Direction = chaosv1alpha1.Both
+		target := o.target.podSelector(cctx.Namespace)
+		delay.Spec.Target = &target
+		pods = fmt.Sprintf("%s to %s", selector, o.target)
+	}
+	if err := cctx.Generic.Create(cctx, &delay); err != nil {
+		return fmt.Errorf("creating delay for %s: %w", pods, err), nil
+	}
+	cctx.Report.Event("chaos", name, fmt.Sprintf("delay pods %s by %s (jitter %s)", pods, latency, jitter))
+	return register(cctx, &delay)
+}
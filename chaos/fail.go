@@ -2,6 +2,7 @@ package chaos
 
 import (
 	"context"
+	"fmt"
 
 	chaosv1alpha1 "github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
 
@@ -14,21 +15,23 @@ type Teardown func(context.Context) error
 
 // Fail the list of pods and prevents them from respawning until teardown is called.
 func Fail(cctx *testcontext.Context, name string, pods ...string) (error, Teardown) {
+	return FailSelected(cctx, name, Pods(pods...))
+}
+
+// FailSelected fails pods chosen by selector and prevents them from respawning until teardown is called.
+func FailSelected(cctx *testcontext.Context, name string, selector Selector) (error, Teardown) {
+	if err := selector.validate(); err != nil {
+		return fmt.Errorf("fail %s: %w", name, err), nil
+	}
 	fail := chaosv1alpha1.PodChaos{}
 	fail.Name = name
 	fail.Namespace = cctx.Namespace
 
 	fail.Spec.Action = chaosv1alpha1.PodFailureAction
-	fail.Spec.Mode = chaosv1alpha1.AllMode
-	fail.Spec.Selector = chaosv1alpha1.PodSelectorSpec{
-		Pods: map[string][]string{
-			cctx.Namespace: pods,
-		},
-	}
+	fail.Spec.PodSelector = selector.podSelector(cctx.Namespace)
 	if err := cctx.Generic.Create(cctx, &fail); err != nil {
 		return err, nil
 	}
-	return nil, func(ctx context.Context) error {
-		return cctx.Generic.Delete(ctx, &fail)
-	}
+	cctx.Report.Event("chaos", name, fmt.Sprintf("fail pods %s", selector))
+	return register(cctx, &fail)
 }
@@ -1,7 +1,6 @@
 package chaos
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
@@ -12,22 +11,25 @@ import (
 
 // Partition2 partitions pods in array a from pods in array b.
 func Partition2(ctx *testcontext.Context, name string, a, b []string) (error, Teardown) {
+	return PartitionSelected(ctx, name, Pods(a...), Pods(b...))
+}
+
+// PartitionSelected partitions pods chosen by selector a from pods chosen by selector b.
+func PartitionSelected(ctx *testcontext.Context, name string, a, b Selector) (error, Teardown) {
+	for _, selector := range []Selector{a, b} {
+		if err := selector.validate(); err != nil {
+			return fmt.Errorf("partition %s: %w", name, err), nil
+		}
+	}
 	partition := chaosv1alpha1.NetworkChaos{}
 	partition.Name = name
 	partition.Namespace = ctx.Namespace
 
 	partition.Spec.Action = chaosv1alpha1.PartitionAction
-	partition.Spec.Mode = chaosv1alpha1.AllMode
-	partition.Spec.Selector.Pods = map[string][]string{
-		ctx.Namespace: a,
-	}
+	partition.Spec.PodSelector = a.podSelector(ctx.Namespace)
 	partition.Spec.Direction = chaosv1alpha1.Both
-	partition.Spec.Target = &chaosv1alpha1.PodSelector{
-		Mode: chaosv1alpha1.AllMode,
-	}
-	partition.Spec.Target.Selector.Pods = map[string][]string{
-		ctx.Namespace: b,
-	}
+	target := b.podSelector(ctx.Namespace)
+	partition.Spec.Target = &target
 
 	desired := partition.DeepCopy()
 	_, err := controllerutil.CreateOrUpdate(ctx, ctx.Generic, &partition, func() error {
@@ -35,10 +37,9 @@ func Partition2(ctx *testcontext.Context, name string, a, b []string) (error, Te
 		return nil
 	})
 	if err != nil {
-		return fmt.Errorf("creating partition for %v | %v: %w", a, b, err), nil
+		return fmt.Errorf("creating partition for %s | %s: %w", a, b, err), nil
 	}
+	ctx.Report.Event("chaos", name, fmt.Sprintf("partition %s from %s", a, b))
 
-	return err, func(rctx context.Context) error {
-		return ctx.Generic.Delete(rctx, &partition)
-	}
+	return register(ctx, &partition)
 }
@@ -0,0 +1,23 @@
+package chaos
+
+import (
+	"time"
+
+	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+)
+
+// FailPoet fails poet servers until teardown is called.
+func FailPoet(cctx *testcontext.Context, name string) (error, Teardown) {
+	return FailSelected(cctx, name, Poets())
+}
+
+// PartitionPoet partitions poet servers from smeshers. Bootnodes are not partitioned,
+// as they are used by poet as gateways.
+func PartitionPoet(cctx *testcontext.Context, name string) (error, Teardown) {
+	return PartitionSelected(cctx, name, Poets(), Smeshers())
+}
+
+// DelayPoet adds latency with jitter to the traffic between poet servers and their gateways (bootnodes).
+func DelayPoet(cctx *testcontext.Context, name string, latency, jitter time.Duration) (error, Teardown) {
+	return DelaySelected(cctx, name, latency, jitter, Poets(), DelayTarget(Bootnodes()))
+}
@@ -0,0 +1,103 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	chaosv1alpha1 "github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+)
+
+// register tracks created chaos object in the context, so that it is removed on cleanup
+// if test didn't call teardown, and waits until chaos is injected.
+// Returned teardown deletes the object and waits until chaos is recovered.
+func register(cctx *testcontext.Context, obj object) (error, Teardown) {
+	cctx.RegisterChaos(obj)
+	teardown := func(ctx context.Context) error {
+		cctx.Report.Event("chaos", obj.GetName(), "teardown")
+		if err := cctx.Generic.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete chaos %s: %w", obj.GetName(), err)
+		}
+		if err := waitRecovered(ctx, cctx, obj); err != nil {
+			return err
+		}
+		cctx.UnregisterChaos(obj)
+		return nil
+	}
+	if err := waitInjected(cctx, obj); err != nil {
+		cctx.Report.Event("chaos", obj.GetName(), err.Error())
+		if terr := teardown(cctx); terr != nil {
+			cctx.Log.Errorw("failed to teardown chaos", "name", obj.GetName(), "error", terr)
+		}
+		return err, nil
+	}
+	return nil, teardown
+}
+
+// List returns all chaos objects in the namespace of the context.
+func List(cctx *testcontext.Context) ([]client.Object, error) {
+	var (
+		rst      []client.Object
+		pods     chaosv1alpha1.PodChaosList
+		networks chaosv1alpha1.NetworkChaosList
+	)
+	if err := cctx.Generic.List(cctx, &pods, client.InNamespace(cctx.Namespace)); err != nil {
+		return nil, fmt.Errorf("list pod chaos: %w", err)
+	}
+	for i := range pods.Items {
+		rst = append(rst, &pods.Items[i])
+	}
+	if err := cctx.Generic.List(cctx, &networks, client.InNamespace(cctx.Namespace)); err != nil {
+		return nil, fmt.Errorf("list network chaos: %w", err)
+	}
+	for i := range networks.Items {
+		rst = append(rst, &networks.Items[i])
+	}
+	return rst, nil
+}
+
+// Selected returns names of the pods that were chosen by the chaos object with the name.
+// It is used to find pods that were chosen by chaos-mesh when selector is Percent or Random.
+func Selected(cctx *testcontext.Context, name string) ([]string, error) {
+	objects, err := List(cctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objects {
+		if obj.GetName() != name {
+			continue
+		}
+		var pods []string
+		for _, record := range obj.(object).GetStatus().Experiment.Records {
+			// record id is namespace/pod
+			parts := strings.SplitN(record.Id, "/", 3)
+			if len(parts) < 2 {
+				return nil, fmt.Errorf("unexpected record %q in chaos %s", record.Id, name)
+			}
+			pods = append(pods, parts[1])
+		}
+		return pods, nil
+	}
+	return nil, fmt.Errorf("chaos %s not found", name)
+}
+
+// ClearAll deletes all chaos objects in the namespace of the context,
+// including objects that were not created by this test.
+func ClearAll(cctx *testcontext.Context) error {
+	objects, err := List(cctx)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objects {
+		if err := cctx.Generic.Delete(cctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete chaos %s: %w", obj.GetName(), err)
+		}
+		cctx.UnregisterChaos(obj)
+		cctx.Report.Event("chaos", obj.GetName(), "cleared")
+	}
+	return nil
+}
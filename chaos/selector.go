@@ -0,0 +1,99 @@
+package chaos
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	chaosv1alpha1 "github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+)
+
+// Selector chooses pods for the chaos action. By default all matched pods are selected,
+// use Percent or Random to select a subset. Subset is chosen by chaos-mesh.
+type Selector struct {
+	pods   []string
+	labels map[string]string
+	mode   chaosv1alpha1.SelectorMode
+	value  string
+}
+
+// Pods selects pods by name.
+func Pods(names ...string) Selector {
+	return Selector{pods: names, mode: chaosv1alpha1.AllMode}
+}
+
+// App selects pods by the app label, for example boot, smesher or poet.
+// Pods that are added to the cluster later are also matched, as long
+// as they exist when chaos is created.
+func App(app string) Selector {
+	return Selector{labels: map[string]string{"app": app}, mode: chaosv1alpha1.AllMode}
+}
+
+// Bootnodes selects all bootnodes.
+func Bootnodes() Selector {
+	return App("boot")
+}
+
+// Smeshers selects all smeshers that are not bootnodes.
+func Smeshers() Selector {
+	return App("smesher")
+}
+
+// Poets selects all poet servers.
+func Poets() Selector {
+	return App("poet")
+}
+
+// Percent selects fixed percent of the matched pods.
+func (s Selector) Percent(percent int) Selector {
+	s.mode = chaosv1alpha1.FixedPercentMode
+	s.value = strconv.Itoa(percent)
+	return s
+}
+
+// Random selects n random pods from the matched pods.
+func (s Selector) Random(n int) Selector {
+	s.mode = chaosv1alpha1.FixedMode
+	s.value = strconv.Itoa(n)
+	return s
+}
+
+func (s Selector) String() string {
+	var target string
+	if len(s.pods) > 0 {
+		target = strings.Join(s.pods, ",")
+	} else {
+		parts := []string{}
+		for key, value := range s.labels {
+			parts = append(parts, key+"="+value)
+		}
+		target = strings.Join(parts, ",")
+	}
+	switch s.mode {
+	case chaosv1alpha1.FixedPercentMode:
+		return fmt.Sprintf("%s%% of %s", s.value, target)
+	case chaosv1alpha1.FixedMode:
+		return fmt.Sprintf("%s random of %s", s.value, target)
+	}
+	return target
+}
+
+// validate returns an error if selector doesn't have pods or labels, as chaos-mesh would
+// select every pod in the namespace in such case.
+func (s Selector) validate() error {
+	if len(s.pods) == 0 && len(s.labels) == 0 {
+		return fmt.Errorf("selector must include pods or labels")
+	}
+	return nil
+}
+
+func (s Selector) podSelector(namespace string) chaosv1alpha1.PodSelector {
+	selector := chaosv1alpha1.PodSelector{Mode: s.mode, Value: s.value}
+	if len(s.pods) > 0 {
+		selector.Selector.Pods = map[string][]string{namespace: s.pods}
+	} else {
+		selector.Selector.Namespaces = []string{namespace}
+		selector.Selector.LabelSelectors = s.labels
+	}
+	return selector
+}
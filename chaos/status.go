@@ -0,0 +1,101 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	chaosv1alpha1 "github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+)
+
+const (
+	injectTimeout  = 2 * time.Minute
+	recoverTimeout = 2 * time.Minute
+	statusInterval = time.Second
+
+	// TeardownTimeout is enough for teardown to delete chaos and wait until it is recovered.
+	TeardownTimeout = recoverTimeout + 30*time.Second
+)
+
+// object is a chaos object that reports status of injection.
+type object interface {
+	client.Object
+	GetStatus() *chaosv1alpha1.ChaosStatus
+}
+
+func hasCondition(obj object, condition chaosv1alpha1.ChaosConditionType) bool {
+	for _, cond := range obj.GetStatus().Conditions {
+		if cond.Type == condition {
+			return cond.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func conditions(obj object) string {
+	parts := []string{}
+	for _, cond := range obj.GetStatus().Conditions {
+		part := fmt.Sprintf("%s=%s", cond.Type, cond.Status)
+		if len(cond.Reason) > 0 {
+			part += fmt.Sprintf(" (%s)", cond.Reason)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// waitStatus polls object until done returns true or timeout expires.
+// If object is deleted waitStatus returns apierrors.IsNotFound error.
+func waitStatus(ctx context.Context, cctx *testcontext.Context, obj object,
+	timeout time.Duration, done func(object) bool) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(statusInterval)
+	defer ticker.Stop()
+	for {
+		if err := cctx.Generic.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("%w. last conditions: %s", ctx.Err(), conditions(obj))
+			}
+			return err
+		}
+		if done(obj) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w. last conditions: %s", ctx.Err(), conditions(obj))
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitInjected waits until chaos is injected into all selected pods.
+func waitInjected(cctx *testcontext.Context, obj object) error {
+	err := waitStatus(cctx, cctx, obj, injectTimeout, func(obj object) bool {
+		return hasCondition(obj, chaosv1alpha1.ConditionAllInjected)
+	})
+	if err != nil {
+		return fmt.Errorf("chaos %s is not injected after %s: %w", obj.GetName(), injectTimeout, err)
+	}
+	return nil
+}
+
+// waitRecovered waits until chaos is recovered in all selected pods after object was deleted.
+// Object is removed by chaos-mesh only after recovery.
+func waitRecovered(ctx context.Context, cctx *testcontext.Context, obj object) error {
+	err := waitStatus(ctx, cctx, obj, recoverTimeout, func(obj object) bool {
+		return hasCondition(obj, chaosv1alpha1.ConditionAllRecovered)
+	})
+	if err == nil || apierrors.IsNotFound(err) {
+		return nil
+	}
+	return fmt.Errorf("chaos %s is not recovered after %s: %w", obj.GetName(), recoverTimeout, err)
+}
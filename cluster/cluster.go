@@ -14,10 +14,14 @@ const (
 	defaultNetID     = 777
 	poetSvc          = "poet"
 	bootnodesPrefix  = "boot"
+	smesherPrefix    = "smesher"
 	poetPort         = 80
 	defaultBootnodes = 2
 )
 
+// MetricsPort is a port for prometheus metrics endpoint on every smesher.
+const MetricsPort = 1010
+
 func headlessSvc(name string) string {
 	return name + "-headless"
 }
@@ -36,6 +40,21 @@ func WithSmesherFlag(flag DeploymentFlag) Opt {
 	}
 }
 
+// WithConfig allows to modify go-spacemesh config that is mounted into every smesher.
+func WithConfig(modify func(*Config)) Opt {
+	return func(c *Cluster) {
+		modify(&c.config)
+	}
+}
+
+// WithMetrics enables prometheus metrics endpoint on every smesher.
+func WithMetrics() Opt {
+	return func(c *Cluster) {
+		c.addFlag(Metrics())
+		c.addFlag(MetricsPortFlag(MetricsPort))
+	}
+}
+
 // WithKeys generates n prefunded keys.
 func WithKeys(n int) Opt {
 	return func(c *Cluster) {
@@ -60,7 +79,7 @@ func Default(cctx *testcontext.Context, opts ...Opt) (*Cluster, error) {
 
 // New initializes Cluster with options.
 func New(cctx *testcontext.Context, opts ...Opt) *Cluster {
-	cluster := &Cluster{smesherFlags: map[string]DeploymentFlag{}}
+	cluster := newCluster()
 	cluster.addFlag(GenesisTime(time.Now().Add(cctx.BootstrapDuration)))
 	cluster.addFlag(TargetOutbound(defaultTargetOutbound(cctx.ClusterSize)))
 	cluster.addFlag(NetworkID(defaultNetID))
@@ -71,12 +90,28 @@ func New(cctx *testcontext.Context, opts ...Opt) *Cluster {
 	if len(cluster.keys) > 0 {
 		cluster.addFlag(Accounts(genGenesis(cluster.keys)))
 	}
+	cctx.Report.Param("layer-duration", time.Duration(cluster.config.Main.LayerDuration))
+	cctx.Report.Param("layers-per-epoch", cluster.config.Main.LayersPerEpoch)
 	return cluster
 }
 
+func newCluster() *Cluster {
+	return &Cluster{
+		smesherFlags: map[string]DeploymentFlag{},
+		config:       DefaultConfig(),
+		poetConfig:   DefaultPoetConfig(),
+		resources:    map[Group]Resources{},
+		placement:    map[Group]Placement{},
+	}
+}
+
 // Cluster for managing state of the spacemesh cluster.
 type Cluster struct {
 	smesherFlags map[string]DeploymentFlag
+	config       Config
+	poetConfig   PoetConfig
+	resources    map[Group]Resources
+	placement    map[Group]Placement
 
 	accounts
 
@@ -90,6 +125,11 @@ func (c *Cluster) addFlag(flag DeploymentFlag) {
 	c.smesherFlags[flag.Name] = flag
 }
 
+// Config returns go-spacemesh config used by the cluster.
+func (c *Cluster) Config() Config {
+	return c.config
+}
+
 // AddPoet ...
 func (c *Cluster) AddPoet(cctx *testcontext.Context) error {
 	if c.bootnodes == 0 {
@@ -98,16 +138,27 @@ func (c *Cluster) AddPoet(cctx *testcontext.Context) error {
 	if len(c.poets) == 1 {
 		return fmt.Errorf("only one poet is supported")
 	}
+	if err := c.capacityControl(cctx, PoetGroup, 1); err != nil {
+		return err
+	}
+	n := c.bootnodes
+	if c.poetConfig.Gateways > 0 && c.poetConfig.Gateways < n {
+		n = c.poetConfig.Gateways
+	}
 	gateways := []string{}
-	for _, bootnode := range c.clients[:c.bootnodes] {
+	for _, bootnode := range c.clients[:n] {
 		gateways = append(gateways, fmt.Sprintf("dns:///%s.%s:9092", bootnode.Name, headlessSvc(bootnodesPrefix)))
 	}
-	endpoint, err := deployPoet(cctx, gateways...)
+	endpoint, err := deployPoet(cctx, c.poetConfig, c.groupResources(PoetGroup), c.groupPlacement(PoetGroup), gateways...)
 	if err != nil {
 		return err
 	}
 	c.poets = append(c.poets, endpoint)
-	return nil
+	cctx.Report.Param("poets", len(c.poets))
+	cctx.Report.Param("poet-image", c.poetConfig.image(cctx.PoetImage))
+	cctx.Report.Param("poet-duration", c.poetConfig.Duration)
+	cctx.Report.Param("poet-n", c.poetConfig.N)
+	return c.persist(cctx)
 }
 
 func (c *Cluster) resourceControl(cctx *testcontext.Context, n int) error {
@@ -123,11 +174,21 @@ func (c *Cluster) AddBootnodes(cctx *testcontext.Context, n int) error {
 	if err := c.resourceControl(cctx, n); err != nil {
 		return err
 	}
+	if err := c.capacityControl(cctx, BootGroup, n); err != nil {
+		return err
+	}
+	if err := deployKeys(cctx, c.keys); err != nil {
+		return err
+	}
+	if err := deployConfig(cctx, &c.config); err != nil {
+		return err
+	}
 	flags := []DeploymentFlag{}
 	for _, flag := range c.smesherFlags {
 		flags = append(flags, flag)
 	}
-	clients, err := deployNodes(cctx, bootnodesPrefix, c.bootnodes+n, flags)
+	clients, err := deployNodes(cctx, bootnodesPrefix, c.bootnodes+n, flags,
+		c.groupResources(BootGroup), c.groupPlacement(BootGroup))
 	if err != nil {
 		return err
 	}
@@ -136,7 +197,8 @@ func (c *Cluster) AddBootnodes(cctx *testcontext.Context, n int) error {
 	c.clients = append(c.clients, clients...)
 	c.clients = append(c.clients, smeshers...)
 	c.bootnodes = len(clients)
-	return nil
+	cctx.Report.Param("bootnodes", c.bootnodes)
+	return c.persist(cctx)
 }
 
 // AddSmeshers ...
@@ -144,12 +206,19 @@ func (c *Cluster) AddSmeshers(cctx *testcontext.Context, n int) error {
 	if err := c.resourceControl(cctx, n); err != nil {
 		return err
 	}
+	if err := c.capacityControl(cctx, SmesherGroup, n); err != nil {
+		return err
+	}
+	if err := deployConfig(cctx, &c.config); err != nil {
+		return err
+	}
 	flags := []DeploymentFlag{}
 	for _, flag := range c.smesherFlags {
 		flags = append(flags, flag)
 	}
 	flags = append(flags, Bootnodes(extractP2PEndpoints(c.clients[:c.bootnodes])...))
-	clients, err := deployNodes(cctx, "smesher", c.smeshers+n, flags)
+	clients, err := deployNodes(cctx, smesherPrefix, c.smeshers+n, flags,
+		c.groupResources(SmesherGroup), c.groupPlacement(SmesherGroup))
 	if err != nil {
 		return err
 	}
@@ -158,7 +227,8 @@ func (c *Cluster) AddSmeshers(cctx *testcontext.Context, n int) error {
 	c.clients = append(c.clients, bootnodes...)
 	c.clients = append(c.clients, clients...)
 	c.smeshers = len(clients)
-	return nil
+	cctx.Report.Param("smeshers", c.smeshers)
+	return c.persist(cctx)
 }
 
 // Total returns total number of clients.
@@ -166,6 +236,11 @@ func (c *Cluster) Total() int {
 	return len(c.clients)
 }
 
+// Bootnodes returns number of bootnodes. Bootnodes are the first clients.
+func (c *Cluster) Bootnodes() int {
+	return c.bootnodes
+}
+
 // Client returns client for i-th node, either bootnode or smesher.
 func (c *Cluster) Client(i int) *NodeClient {
 	return c.clients[i]
@@ -173,11 +248,15 @@ func (c *Cluster) Client(i int) *NodeClient {
 
 // Wait for i-th client to be up.
 func (c *Cluster) Wait(tctx *testcontext.Context, i int) error {
-	nc, err := waitSmesher(tctx, c.Client(i).Name)
+	app := smesherPrefix
+	if i < c.bootnodes {
+		app = bootnodesPrefix
+	}
+	clients, err := waitNodes(tctx, map[string]string{"app": app}, []string{c.Client(i).Name})
 	if err != nil {
 		return err
 	}
-	c.clients[i] = nc
+	c.clients[i] = clients[0]
 	return nil
 }
 
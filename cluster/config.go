@@ -0,0 +1,161 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	apimetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/applyconfigurations/core/v1"
+
+	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+)
+
+const (
+	configMapName  = "spacemesh-config"
+	configFileName = "config.json"
+	configDir      = "/etc/spacemesh"
+)
+
+// Duration is a time.Duration that is encoded as a string in the config file.
+type Duration time.Duration
+
+// MarshalJSON encodes duration in the format parsed by go-spacemesh.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes duration from a string.
+func (d *Duration) UnmarshalJSON(buf []byte) error {
+	var s string
+	if err := json.Unmarshal(buf, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MainConfig is a subset of go-spacemesh base config.
+type MainConfig struct {
+	LayerDuration  Duration `json:"layer-duration"`
+	LayersPerEpoch uint32   `json:"layers-per-epoch"`
+	LayerAvgSize   int      `json:"layer-average-size"`
+}
+
+// HareConfig is a subset of go-spacemesh hare config.
+type HareConfig struct {
+	N                int `json:"hare-committee-size"`
+	ExpectedLeaders  int `json:"hare-exp-leaders"`
+	RoundDurationSec int `json:"hare-round-duration-sec"`
+	WakeupDelta      int `json:"hare-wakeup-delta"`
+	LimitIterations  int `json:"hare-limit-iterations"`
+	LimitConcurrent  int `json:"hare-limit-concurrent"`
+}
+
+// TortoiseConfig is a subset of go-spacemesh tortoise config.
+type TortoiseConfig struct {
+	Hdist         uint32   `json:"tortoise-hdist"`
+	Zdist         uint32   `json:"tortoise-zdist"`
+	WindowSize    uint32   `json:"tortoise-window-size,omitempty"`
+	RerunInterval Duration `json:"tortoise-rerun-interval,omitempty"`
+}
+
+// BeaconConfig is a subset of go-spacemesh beacon config.
+type BeaconConfig struct {
+	Kappa                    uint64   `json:"beacon-kappa"`
+	Q                        string   `json:"beacon-q,omitempty"`
+	Theta                    string   `json:"beacon-theta"`
+	RoundsNumber             uint32   `json:"beacon-rounds-number"`
+	VotesLimit               uint64   `json:"beacon-votes-limit"`
+	GracePeriodDuration      Duration `json:"beacon-grace-period-duration"`
+	ProposalDuration         Duration `json:"beacon-proposal-duration"`
+	FirstVotingRoundDuration Duration `json:"beacon-first-voting-round-duration"`
+	VotingRoundDuration      Duration `json:"beacon-voting-round-duration"`
+	WeakCoinRoundDuration    Duration `json:"beacon-weak-coin-round-duration"`
+}
+
+// PostConfig is a subset of go-spacemesh post config.
+type PostConfig struct {
+	BitsPerLabel  uint `json:"post-bits-per-label"`
+	LabelsPerUnit uint `json:"post-labels-per-unit"`
+	MinNumUnits   uint `json:"post-min-numunits"`
+	MaxNumUnits   uint `json:"post-max-numunits"`
+	K1            uint `json:"post-k1"`
+	K2            uint `json:"post-k2"`
+}
+
+// Config is a structured subset of go-spacemesh config. It is rendered into json,
+// mounted into every smesher using ConfigMap and applied on top of the fastnet preset.
+type Config struct {
+	Main     MainConfig     `json:"main"`
+	Hare     HareConfig     `json:"hare"`
+	Tortoise TortoiseConfig `json:"tortoise"`
+	Beacon   BeaconConfig   `json:"beacon"`
+	Post     PostConfig     `json:"post"`
+}
+
+// DefaultConfig returns config with the same parameters as in fastnet preset.
+// Fields that are not present in Config keep values from the preset.
+func DefaultConfig() Config {
+	return Config{
+		Main: MainConfig{
+			LayerDuration:  Duration(15 * time.Second),
+			LayersPerEpoch: 4,
+			LayerAvgSize:   50,
+		},
+		Hare: HareConfig{
+			N:                800,
+			ExpectedLeaders:  10,
+			RoundDurationSec: 2,
+			WakeupDelta:      3,
+			LimitIterations:  3,
+			LimitConcurrent:  5,
+		},
+		Tortoise: TortoiseConfig{
+			Hdist: 4,
+			Zdist: 4,
+		},
+		Beacon: BeaconConfig{
+			Kappa:                    40,
+			Theta:                    "1/4",
+			RoundsNumber:             4,
+			VotesLimit:               100,
+			GracePeriodDuration:      Duration(30 * time.Second),
+			ProposalDuration:         Duration(2 * time.Second),
+			FirstVotingRoundDuration: Duration(10 * time.Second),
+			VotingRoundDuration:      Duration(2 * time.Second),
+			WeakCoinRoundDuration:    Duration(2 * time.Second),
+		},
+		Post: PostConfig{
+			BitsPerLabel:  8,
+			LabelsPerUnit: 32,
+			MinNumUnits:   2,
+			MaxNumUnits:   4,
+			K1:            2000,
+			K2:            4,
+		},
+	}
+}
+
+// ConfigFlag points go-spacemesh to the config file mounted from ConfigMap.
+func ConfigFlag() DeploymentFlag {
+	return DeploymentFlag{Name: "--config", Value: configDir + "/" + configFileName}
+}
+
+func deployConfig(ctx *testcontext.Context, cfg *Config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+	cm := corev1.ConfigMap(configMapName, ctx.Namespace).
+		WithData(map[string]string{configFileName: string(data)})
+	_, err = ctx.Client.CoreV1().ConfigMaps(ctx.Namespace).Apply(ctx, cm, apimetav1.ApplyOptions{FieldManager: "test"})
+	if err != nil {
+		return fmt.Errorf("apply configmap %s: %w", configMapName, err)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package cluster
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/spacemeshos/ed25519"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/applyconfigurations/core/v1"
+
+	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+)
+
+const (
+	keysSecret = "accounts"
+	keysField  = "keys"
+)
+
+// ErrNotDeployed is returned by Discover if there is no cluster in the namespace.
+var ErrNotDeployed = errors.New("cluster is not deployed")
+
+func deployKeys(cctx *testcontext.Context, keys []*signer) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	encoded := make([]string, 0, len(keys))
+	for _, key := range keys {
+		encoded = append(encoded, hex.EncodeToString(key.PK))
+	}
+	secret := corev1.Secret(keysSecret, cctx.Namespace).
+		WithData(map[string][]byte{keysField: []byte(strings.Join(encoded, "\n"))})
+	_, err := cctx.Client.CoreV1().Secrets(cctx.Namespace).Apply(cctx, secret, apimetav1.ApplyOptions{FieldManager: "test"})
+	if err != nil {
+		return fmt.Errorf("apply secret %s: %w", keysSecret, err)
+	}
+	return nil
+}
+
+func loadKeys(cctx *testcontext.Context) ([]*signer, error) {
+	secret, err := cctx.Client.CoreV1().Secrets(cctx.Namespace).Get(cctx, keysSecret, apimetav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read secret %s: %w", keysSecret, err)
+	}
+	var keys []*signer
+	for _, line := range strings.Split(string(secret.Data[keysField]), "\n") {
+		if len(line) == 0 {
+			continue
+		}
+		pk, err := hex.DecodeString(line)
+		if err != nil || len(pk) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("invalid key in secret %s", keysSecret)
+		}
+		pub := make([]byte, ed25519.PublicKeySize)
+		copy(pub, pk[32:])
+		keys = append(keys, &signer{Pub: pub, PK: pk})
+	}
+	return keys, nil
+}
+
+func loadConfig(cctx *testcontext.Context) (Config, error) {
+	cfg := DefaultConfig()
+	cm, err := cctx.Client.CoreV1().ConfigMaps(cctx.Namespace).Get(cctx, configMapName, apimetav1.GetOptions{})
+	if err != nil {
+		return cfg, fmt.Errorf("read configmap %s: %w", configMapName, err)
+	}
+	if err := json.Unmarshal([]byte(cm.Data[configFileName]), &cfg); err != nil {
+		return cfg, fmt.Errorf("decode config: %w", err)
+	}
+	return cfg, nil
+}
+
+// discoverGroup returns flags and names of the deployed statefulset.
+func discoverGroup(cctx *testcontext.Context, name string) ([]DeploymentFlag, []string, error) {
+	sset, err := cctx.Client.AppsV1().StatefulSets(cctx.Namespace).Get(cctx, name, apimetav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("read statefulset %s: %w", name, err)
+	}
+	replicas := 0
+	if sset.Spec.Replicas != nil {
+		replicas = int(*sset.Spec.Replicas)
+	}
+	names := make([]string, 0, replicas)
+	for i := 0; i < replicas; i++ {
+		names = append(names, fmt.Sprintf("%s-%d", name, i))
+	}
+	var flags []DeploymentFlag
+	for _, container := range sset.Spec.Template.Spec.Containers {
+		flags = append(flags, parseFlags(container.Command)...)
+	}
+	return flags, names, nil
+}
+
+// Discover reconstructs cluster from the objects deployed in the namespace.
+// Flags and poets are loaded from the namespace annotations (see Metadata) if they exist,
+// otherwise they are parsed from the deployed objects.
+// Returns ErrNotDeployed if bootnodes are not deployed.
+func Discover(cctx *testcontext.Context) (*Cluster, error) {
+	cl := newCluster()
+	flags, bootnodes, err := discoverGroup(cctx, bootnodesPrefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(bootnodes) == 0 {
+		return nil, fmt.Errorf("%w in namespace %s", ErrNotDeployed, cctx.Namespace)
+	}
+	meta, err := LoadMetadata(cctx)
+	switch {
+	case err == nil:
+		// annotations are preferred, as they are not mixed with per-group flags
+		flags = meta.Flags
+	case !errors.Is(err, ErrNotDeployed):
+		return nil, err
+	}
+	for _, flag := range flags {
+		cl.addFlag(flag)
+	}
+	_, smeshers, err := discoverGroup(cctx, smesherPrefix)
+	if err != nil {
+		return nil, err
+	}
+	if cl.config, err = loadConfig(cctx); err != nil {
+		return nil, err
+	}
+	if cl.keys, err = loadKeys(cctx); err != nil {
+		return nil, err
+	}
+	if meta != nil {
+		cl.poets = meta.Poets
+	} else {
+		_, err = cctx.Client.CoreV1().Services(cctx.Namespace).Get(cctx, poetSvc, apimetav1.GetOptions{})
+		if err == nil {
+			cl.poets = append(cl.poets, poetEndpoint())
+		} else if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("read poet service: %w", err)
+		}
+	}
+	bootclients, err := waitNodes(cctx, map[string]string{"app": bootnodesPrefix}, bootnodes)
+	if err != nil {
+		return nil, err
+	}
+	smesherclients, err := waitNodes(cctx, map[string]string{"app": smesherPrefix}, smeshers)
+	if err != nil {
+		return nil, err
+	}
+	cl.clients = append(bootclients, smesherclients...)
+	cl.bootnodes = len(bootclients)
+	cl.smeshers = len(smesherclients)
+	cctx.Log.Infow("discovered cluster",
+		"namespace", cctx.Namespace,
+		"bootnodes", cl.bootnodes,
+		"smeshers", cl.smeshers,
+		"poets", len(cl.poets),
+		"keys", len(cl.keys),
+	)
+	return cl, nil
+}
+
+type sharedCluster struct {
+	ready chan struct{}
+	cl    *Cluster
+	err   error
+}
+
+var shared = struct {
+	sync.Mutex
+	clusters map[string]*sharedCluster
+}{clusters: map[string]*sharedCluster{}}
+
+// Reuse returns cluster that is shared by all tests in the same namespace.
+// Cluster is discovered if it was already deployed in the namespace, otherwise it is
+// deployed by the first test with Default and opts. Tests that use shared cluster must not modify it.
+func Reuse(cctx *testcontext.Context, opts ...Opt) (*Cluster, error) {
+	shared.Lock()
+	entry, exist := shared.clusters[cctx.Namespace]
+	if !exist {
+		entry = &sharedCluster{ready: make(chan struct{})}
+		shared.clusters[cctx.Namespace] = entry
+	}
+	shared.Unlock()
+	if !exist {
+		entry.cl, entry.err = Discover(cctx)
+		if errors.Is(entry.err, ErrNotDeployed) {
+			cctx.Log.Infow("deploying shared cluster", "namespace", cctx.Namespace)
+			entry.cl, entry.err = Default(cctx, opts...)
+		}
+		if entry.err != nil {
+			// next test will try again
+			shared.Lock()
+			delete(shared.clusters, cctx.Namespace)
+			shared.Unlock()
+		}
+		close(entry.ready)
+	}
+	select {
+	case <-cctx.Done():
+		return nil, cctx.Err()
+	case <-entry.ready:
+	}
+	return entry.cl, entry.err
+}
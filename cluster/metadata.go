@@ -0,0 +1,136 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apimetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/applyconfigurations/core/v1"
+
+	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+)
+
+const (
+	annotationPrefix = "systest.spacemesh.io/"
+	// metadataManager is different from the manager that creates namespace,
+	// so that annotations are not removed if namespace is applied again.
+	metadataManager = "cluster"
+
+	genesisAnnotation   = annotationPrefix + "genesis-time"
+	networkAnnotation   = annotationPrefix + "network-id"
+	bootnodesAnnotation = annotationPrefix + "bootnodes"
+	smeshersAnnotation  = annotationPrefix + "smeshers"
+	poetsAnnotation     = annotationPrefix + "poets"
+	flagsAnnotation     = annotationPrefix + "flags"
+	imageAnnotation     = annotationPrefix + "image"
+	poetImageAnnotation = annotationPrefix + "poet-image"
+)
+
+// Metadata describes deployed cluster. It is persisted as annotations on the namespace,
+// so that cluster can be reattached after test is completed (for example with -keep).
+// Keys are stored separately in a Secret.
+type Metadata struct {
+	GenesisTime time.Time
+	NetworkID   uint32
+	Bootnodes   int
+	Smeshers    int
+	Poets       []string
+	Flags       []DeploymentFlag
+	Image       string
+	PoetImage   string
+}
+
+func (c *Cluster) metadata(cctx *testcontext.Context) (Metadata, error) {
+	meta := Metadata{
+		Bootnodes: c.bootnodes,
+		Smeshers:  c.smeshers,
+		Poets:     c.poets,
+		Image:     cctx.Image,
+		PoetImage: c.poetConfig.image(cctx.PoetImage),
+	}
+	for _, flag := range c.smesherFlags {
+		meta.Flags = append(meta.Flags, flag)
+	}
+	sort.Slice(meta.Flags, func(i, j int) bool {
+		return meta.Flags[i].Name < meta.Flags[j].Name
+	})
+	timing, err := c.Timing()
+	if err != nil {
+		return Metadata{}, err
+	}
+	meta.GenesisTime = timing.Genesis
+	if flag, exist := c.smesherFlags[NetworkID(0).Name]; exist {
+		id, _ := strconv.ParseUint(flag.Value, 10, 32)
+		meta.NetworkID = uint32(id)
+	}
+	return meta, nil
+}
+
+// persist stores metadata of the cluster as annotations on the namespace.
+func (c *Cluster) persist(cctx *testcontext.Context) error {
+	meta, err := c.metadata(cctx)
+	if err != nil {
+		return err
+	}
+	flags, err := json.Marshal(meta.Flags)
+	if err != nil {
+		return fmt.Errorf("encode flags: %w", err)
+	}
+	ns := corev1.Namespace(cctx.Namespace).WithAnnotations(map[string]string{
+		genesisAnnotation:   meta.GenesisTime.Format(time.RFC3339),
+		networkAnnotation:   strconv.Itoa(int(meta.NetworkID)),
+		bootnodesAnnotation: strconv.Itoa(meta.Bootnodes),
+		smeshersAnnotation:  strconv.Itoa(meta.Smeshers),
+		poetsAnnotation:     strings.Join(meta.Poets, ","),
+		flagsAnnotation:     string(flags),
+		imageAnnotation:     meta.Image,
+		poetImageAnnotation: meta.PoetImage,
+	})
+	_, err = cctx.Client.CoreV1().Namespaces().Apply(cctx, ns, apimetav1.ApplyOptions{FieldManager: metadataManager})
+	if err != nil {
+		return fmt.Errorf("annotate namespace %s: %w", cctx.Namespace, err)
+	}
+	return nil
+}
+
+// LoadMetadata reads metadata of the cluster from the namespace annotations.
+// Returns ErrNotDeployed if namespace doesn't have annotations.
+func LoadMetadata(cctx *testcontext.Context) (*Metadata, error) {
+	ns, err := cctx.Client.CoreV1().Namespaces().Get(cctx, cctx.Namespace, apimetav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("read namespace %s: %w", cctx.Namespace, err)
+	}
+	annotations := ns.Annotations
+	if _, exist := annotations[bootnodesAnnotation]; !exist {
+		return nil, fmt.Errorf("%w in namespace %s", ErrNotDeployed, cctx.Namespace)
+	}
+	meta := &Metadata{
+		Image:     annotations[imageAnnotation],
+		PoetImage: annotations[poetImageAnnotation],
+	}
+	if meta.GenesisTime, err = time.Parse(time.RFC3339, annotations[genesisAnnotation]); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", genesisAnnotation, err)
+	}
+	id, err := strconv.ParseUint(annotations[networkAnnotation], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", networkAnnotation, err)
+	}
+	meta.NetworkID = uint32(id)
+	if meta.Bootnodes, err = strconv.Atoi(annotations[bootnodesAnnotation]); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", bootnodesAnnotation, err)
+	}
+	if meta.Smeshers, err = strconv.Atoi(annotations[smeshersAnnotation]); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", smeshersAnnotation, err)
+	}
+	if poets := annotations[poetsAnnotation]; len(poets) > 0 {
+		meta.Poets = strings.Split(poets, ",")
+	}
+	if err := json.Unmarshal([]byte(annotations[flagsAnnotation]), &meta.Flags); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", flagsAnnotation, err)
+	}
+	return meta, nil
+}
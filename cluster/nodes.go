@@ -1,19 +1,16 @@
 package cluster
 
 import (
-	"context"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
-	spacemeshv1 "github.com/spacemeshos/api/release/go/spacemesh/v1"
 	"google.golang.org/grpc"
-	"google.golang.org/protobuf/types/known/emptypb"
 	apiappsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 	apimetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	appsv1 "k8s.io/client-go/applyconfigurations/apps/v1"
 	corev1 "k8s.io/client-go/applyconfigurations/core/v1"
 	metav1 "k8s.io/client-go/applyconfigurations/meta/v1"
@@ -34,6 +31,12 @@ func (n Node) GRPCEndpoint() string {
 	return fmt.Sprintf("%s:%d", n.IP, n.GRPC)
 }
 
+// MetricsEndpoint returns http endpoint with prometheus metrics.
+// Metrics are served only if cluster was created WithMetrics.
+func (n Node) MetricsEndpoint() string {
+	return fmt.Sprintf("http://%s:%d/metrics", n.IP, MetricsPort)
+}
+
 // P2PEndpoint returns full p2p endpoint, including identity.
 func (n Node) P2PEndpoint() string {
 	return fmt.Sprintf("/ip4/%s/tcp/%d/p2p/%s", n.IP, n.P2P, n.ID)
@@ -43,37 +46,34 @@ func (n Node) P2PEndpoint() string {
 type NodeClient struct {
 	Node
 	*grpc.ClientConn
+
+	// cctx is used to read current ip of the pod when node is redialed.
+	cctx *testcontext.Context
 }
 
 // deployPoet accepts address of the gateway (to use dns resolver add dns:/// prefix to the address)
 // and output ip of the poet.
-func deployPoet(ctx *testcontext.Context, gateways ...string) (string, error) {
+func deployPoet(ctx *testcontext.Context, cfg PoetConfig, res Resources, placement Placement, gateways ...string) (string, error) {
 	args := []string{}
 	for _, gateway := range gateways {
 		args = append(args, "--gateway="+gateway)
 	}
-	args = append(args,
-		"--restlisten=0.0.0.0:"+strconv.Itoa(poetPort),
-		"--duration=30s",
-		"--n=10",
-	)
+	args = append(args, "--restlisten=0.0.0.0:"+strconv.Itoa(poetPort))
+	args = append(args, cfg.args()...)
 	labels := map[string]string{"app": "poet"}
 	pod := corev1.Pod("poet", ctx.Namespace).
 		WithLabels(labels).
 		WithSpec(
-			corev1.PodSpec().
-				WithNodeSelector(ctx.NodeSelector).
+			placement.apply(corev1.PodSpec(), ctx.NodeSelector, labels).
 				WithContainers(corev1.Container().
 					WithName("poet").
-					WithImage(ctx.PoetImage).
+					WithImage(cfg.image(ctx.PoetImage)).
 					WithArgs(args...).
 					WithPorts(corev1.ContainerPort().WithName("rest").WithProtocol("TCP").WithContainerPort(poetPort)).
-					WithResources(corev1.ResourceRequirements().WithRequests(
-						v1.ResourceList{
-							v1.ResourceCPU:    resource.MustParse("0.5"),
-							v1.ResourceMemory: resource.MustParse("1Gi"),
-						},
-					)),
+					WithReadinessProbe(readinessProbe(poetPort)).
+					WithLivenessProbe(livenessProbe(poetPort)).
+					WithResources(resourceRequirements(res)).
+					WithEnv(envVars(res)...),
 				),
 		)
 	_, err := ctx.Client.CoreV1().Pods(ctx.Namespace).Apply(ctx, pod, apimetav1.ApplyOptions{FieldManager: "test"})
@@ -93,34 +93,100 @@ func deployPoet(ctx *testcontext.Context, gateways ...string) (string, error) {
 		return "", fmt.Errorf("apply poet service: %w", err)
 	}
 
-	_, err = waitPod(ctx, *pod.Name)
-	if err != nil {
+	if err := waitPods(ctx, ctx, labels, []string{*pod.Name}, nil); err != nil {
 		return "", err
 	}
 	return fmt.Sprintf("%s:%d", *svc.Name, poetPort), nil
 }
 
-func waitPod(ctx *testcontext.Context, name string) (*v1.Pod, error) {
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
-		}
-		pod, err := ctx.Client.CoreV1().Pods(ctx.Namespace).Get(ctx, name, apimetav1.GetOptions{})
-		if err != nil {
-			return nil, fmt.Errorf("read pod %s: %w", name, err)
+// readinessProbe marks pod as ready once the port accepts tcp connections.
+// Pod is not ready only after several consecutive failures, so that it doesn't flap under load.
+func readinessProbe(port int32) *corev1.ProbeApplyConfiguration {
+	return corev1.Probe().
+		WithTCPSocket(corev1.TCPSocketAction().WithPort(intstr.FromInt(int(port)))).
+		WithPeriodSeconds(2).
+		WithFailureThreshold(3)
+}
+
+// livenessProbe restarts the container if the port doesn't accept tcp connections
+// for a prolonged period of time.
+func livenessProbe(port int32) *corev1.ProbeApplyConfiguration {
+	return corev1.Probe().
+		WithTCPSocket(corev1.TCPSocketAction().WithPort(intstr.FromInt(int(port)))).
+		WithInitialDelaySeconds(60).
+		WithPeriodSeconds(10).
+		WithFailureThreshold(6)
+}
+
+func resourceRequirements(res Resources) *corev1.ResourceRequirementsApplyConfiguration {
+	requirements := corev1.ResourceRequirements()
+	if len(res.Requests) > 0 {
+		requirements = requirements.WithRequests(res.Requests)
+	}
+	if len(res.Limits) > 0 {
+		requirements = requirements.WithLimits(res.Limits)
+	}
+	return requirements
+}
+
+func envVars(res Resources) []*corev1.EnvVarApplyConfiguration {
+	var env []*corev1.EnvVarApplyConfiguration
+	if res.GoMaxProcs > 0 {
+		env = append(env, corev1.EnvVar().WithName("GOMAXPROCS").WithValue(strconv.Itoa(res.GoMaxProcs)))
+	}
+	return env
+}
+
+const smesherBinary = "/bin/go-spacemesh"
+
+// baseCommand is a list of flags that are the same for every smesher
+// and not managed by the Cluster.
+//
+// Config file is applied on top of the fastnet preset, so that preset fields
+// that are not modelled by Config (e.g. sync and smeshing options) are preserved.
+var baseCommand = []string{
+	"--preset=fastnet",
+	ConfigFlag().Flag(),
+	"--smeshing-start=true",
+	"--smeshing-opts-datadir=/data/post",
+	"-d=/data/state",
+	"--log-encoder=json",
+}
+
+// parseFlags extracts flags managed by the Cluster from the smesher command.
+func parseFlags(cmd []string) []DeploymentFlag {
+	base := map[string]struct{}{}
+	for _, arg := range baseCommand {
+		base[arg] = struct{}{}
+	}
+	var flags []DeploymentFlag
+	for _, arg := range cmd {
+		if _, exist := base[arg]; exist || !strings.HasPrefix(arg, "--") {
+			continue
 		}
-		switch pod.Status.Phase {
-		case v1.PodFailed:
-			return nil, fmt.Errorf("pod failed %s", name)
-		case v1.PodRunning:
-			return pod, nil
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			continue
 		}
+		flags = append(flags, DeploymentFlag{Name: parts[0], Value: parts[1]})
 	}
+	return flags
 }
 
-func deployNodes(ctx *testcontext.Context, name string, replicas int, flags []DeploymentFlag) ([]*NodeClient, error) {
+func volumeClaimSpec(res Resources) *corev1.PersistentVolumeClaimSpecApplyConfiguration {
+	spec := corev1.PersistentVolumeClaimSpec().
+		WithAccessModes(v1.ReadWriteOnce).
+		WithResources(corev1.ResourceRequirements().
+			WithRequests(v1.ResourceList{v1.ResourceStorage: res.Storage}))
+	// explicitly empty storage class disables dynamic provisioning, leave it unset to use default class
+	if len(res.StorageClass) > 0 {
+		spec = spec.WithStorageClassName(res.StorageClass)
+	}
+	return spec
+}
+
+func deployNodes(ctx *testcontext.Context, name string, replicas int, flags []DeploymentFlag,
+	res Resources, placement Placement) ([]*NodeClient, error) {
 	labels := map[string]string{
 		"app": name,
 	}
@@ -138,14 +204,8 @@ func deployNodes(ctx *testcontext.Context, name string, replicas int, flags []De
 	if err != nil {
 		return nil, fmt.Errorf("apply headless service: %w", err)
 	}
-	cmd := []string{
-		"/bin/go-spacemesh",
-		"--preset=fastnet",
-		"--smeshing-start=true",
-		"--smeshing-opts-datadir=/data/post",
-		"-d=/data/state",
-		"--log-encoder=json",
-	}
+	cmd := []string{smesherBinary}
+	cmd = append(cmd, baseCommand...)
 	for _, flag := range flags {
 		cmd = append(cmd, flag.Flag())
 	}
@@ -157,17 +217,16 @@ func deployNodes(ctx *testcontext.Context, name string, replicas int, flags []De
 			WithServiceName(*svc.Name).
 			WithVolumeClaimTemplates(
 				corev1.PersistentVolumeClaim("data", ctx.Namespace).
-					WithSpec(corev1.PersistentVolumeClaimSpec().
-						WithAccessModes(v1.ReadWriteOnce).
-						WithStorageClassName("standard").
-						WithResources(corev1.ResourceRequirements().
-							WithRequests(v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")}))),
+					WithSpec(volumeClaimSpec(res)),
 			).
 			WithSelector(metav1.LabelSelector().WithMatchLabels(labels)).
 			WithTemplate(corev1.PodTemplateSpec().
 				WithLabels(labels).
-				WithSpec(corev1.PodSpec().
-					WithNodeSelector(ctx.NodeSelector).
+				WithSpec(placement.apply(corev1.PodSpec(), ctx.NodeSelector, labels).
+					WithVolumes(corev1.Volume().
+						WithName("config").
+						WithConfigMap(corev1.ConfigMapVolumeSource().WithName(configMapName)),
+					).
 					WithContainers(corev1.Container().
 						WithName("smesher").
 						WithImage(ctx.Image).
@@ -175,17 +234,16 @@ func deployNodes(ctx *testcontext.Context, name string, replicas int, flags []De
 						WithPorts(
 							corev1.ContainerPort().WithContainerPort(7513).WithName("p2p"),
 							corev1.ContainerPort().WithContainerPort(9092).WithName("grpc"),
+							corev1.ContainerPort().WithContainerPort(MetricsPort).WithName("metrics"),
 						).
 						WithVolumeMounts(
 							corev1.VolumeMount().WithName("data").WithMountPath("/data"),
+							corev1.VolumeMount().WithName("config").WithMountPath(configDir),
 						).
-						WithResources(corev1.ResourceRequirements().WithRequests(
-							v1.ResourceList{
-								v1.ResourceCPU:    resource.MustParse("0.5"),
-								v1.ResourceMemory: resource.MustParse("1Gi"),
-							},
-						)).
-						WithEnv(corev1.EnvVar().WithName("GOMAXPROCS").WithValue("2")).
+						WithReadinessProbe(readinessProbe(9092)).
+						WithLivenessProbe(livenessProbe(9092)).
+						WithResources(resourceRequirements(res)).
+						WithEnv(envVars(res)...).
 						WithCommand(cmd...),
 					)),
 			),
@@ -196,55 +254,11 @@ func deployNodes(ctx *testcontext.Context, name string, replicas int, flags []De
 	if err != nil {
 		return nil, fmt.Errorf("apply statefulset: %w", err)
 	}
-	var result []*NodeClient
+	names := make([]string, 0, replicas)
 	for i := 0; i < replicas; i++ {
-		nc, err := waitSmesher(ctx, fmt.Sprintf("%s-%d", *sset.Name, i))
-		if err != nil {
-			return nil, err
-		}
-		result = append(result, nc)
-	}
-	return result, nil
-}
-
-func waitSmesher(tctx *testcontext.Context, name string) (*NodeClient, error) {
-	attempt := func() (*NodeClient, error) {
-		pod, err := waitPod(tctx, name)
-		if err != nil {
-			return nil, err
-		}
-		node := Node{
-			Name: name,
-			IP:   pod.Status.PodIP,
-			P2P:  7513,
-			GRPC: 9092,
-		}
-		rctx, cancel := context.WithTimeout(tctx, 5*time.Second)
-		defer cancel()
-		conn, err := grpc.DialContext(rctx, node.GRPCEndpoint(), grpc.WithInsecure(), grpc.WithBlock())
-		if err != nil {
-			return nil, err
-		}
-		dbg := spacemeshv1.NewDebugServiceClient(conn)
-		info, err := dbg.NetworkInfo(tctx, &emptypb.Empty{})
-		if err != nil {
-			return nil, err
-		}
-		node.ID = info.Id
-		return &NodeClient{
-			Node:       node,
-			ClientConn: conn,
-		}, nil
+		names = append(names, fmt.Sprintf("%s-%d", *sset.Name, i))
 	}
-	const attempts = 10
-	for i := 1; i <= attempts; i++ {
-		if nc, err := attempt(); err != nil && i == attempts {
-			return nil, err
-		} else if err == nil {
-			return nc, nil
-		}
-	}
-	panic("unreachable")
+	return waitNodes(ctx, labels, names)
 }
 
 // DeploymentFlag allows to configure specific flags for application binaries.
@@ -257,16 +271,21 @@ func (d DeploymentFlag) Flag() string {
 	return d.Name + "=" + d.Value
 }
 
-// RerunInterval flag.
-func RerunInterval(duration time.Duration) DeploymentFlag {
-	return DeploymentFlag{Name: "--tortoise-rerun-interval", Value: duration.String()}
-}
-
 // PoetEndpoint flag.
 func PoetEndpoint(endpoint string) DeploymentFlag {
 	return DeploymentFlag{Name: "--poet-server", Value: endpoint}
 }
 
+// Metrics flag enables prometheus metrics.
+func Metrics() DeploymentFlag {
+	return DeploymentFlag{Name: "--metrics", Value: "true"}
+}
+
+// MetricsPortFlag sets port for prometheus metrics endpoint.
+func MetricsPortFlag(port int) DeploymentFlag {
+	return DeploymentFlag{Name: "--metrics-port", Value: strconv.Itoa(port)}
+}
+
 // NetworkID flag.
 func NetworkID(id uint32) DeploymentFlag {
 	return DeploymentFlag{Name: "--network-id", Value: strconv.Itoa(int(id))}
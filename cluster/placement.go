@@ -0,0 +1,90 @@
+package cluster
+
+import (
+	v1 "k8s.io/api/core/v1"
+	corev1 "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1 "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
+const (
+	// HostnameTopology places every pod on a separate k8s node.
+	HostnameTopology = "kubernetes.io/hostname"
+	// ZoneTopology places pods in different zones.
+	ZoneTopology = "topology.kubernetes.io/zone"
+)
+
+// Placement controls where pods of the group are scheduled.
+type Placement struct {
+	// NodeSelector is merged with the node selector from the test context.
+	NodeSelector map[string]string
+	Tolerations  []v1.Toleration
+	// AntiAffinity is a topology key. Pods in the group will prefer to be scheduled
+	// in different domains of that topology.
+	AntiAffinity string
+	// RequireAntiAffinity makes AntiAffinity a hard requirement for the scheduler.
+	RequireAntiAffinity bool
+	// Spread is a list of topology keys. Pods in the group will be distributed evenly
+	// across domains of every topology, with max skew equal to 1.
+	Spread []string
+}
+
+// WithPlacement sets placement for pods in the group.
+func WithPlacement(group Group, placement Placement) Opt {
+	return func(c *Cluster) {
+		c.placement[group] = placement
+	}
+}
+
+func (c *Cluster) groupPlacement(group Group) Placement {
+	return c.placement[group]
+}
+
+func (p Placement) nodeSelector(global map[string]string) map[string]string {
+	selector := map[string]string{}
+	for key, value := range global {
+		selector[key] = value
+	}
+	for key, value := range p.NodeSelector {
+		selector[key] = value
+	}
+	return selector
+}
+
+// apply adds placement constraints to the spec for pods selected by labels.
+func (p Placement) apply(spec *corev1.PodSpecApplyConfiguration, global, labels map[string]string) *corev1.PodSpecApplyConfiguration {
+	spec = spec.WithNodeSelector(p.nodeSelector(global))
+	for _, toleration := range p.Tolerations {
+		applied := corev1.Toleration().
+			WithKey(toleration.Key).
+			WithOperator(toleration.Operator).
+			WithValue(toleration.Value).
+			WithEffect(toleration.Effect)
+		if toleration.TolerationSeconds != nil {
+			applied = applied.WithTolerationSeconds(*toleration.TolerationSeconds)
+		}
+		spec = spec.WithTolerations(applied)
+	}
+	if len(p.AntiAffinity) > 0 {
+		term := corev1.PodAffinityTerm().
+			WithTopologyKey(p.AntiAffinity).
+			WithLabelSelector(metav1.LabelSelector().WithMatchLabels(labels))
+		anti := corev1.PodAntiAffinity()
+		if p.RequireAntiAffinity {
+			anti = anti.WithRequiredDuringSchedulingIgnoredDuringExecution(term)
+		} else {
+			anti = anti.WithPreferredDuringSchedulingIgnoredDuringExecution(
+				corev1.WeightedPodAffinityTerm().WithWeight(100).WithPodAffinityTerm(term),
+			)
+		}
+		spec = spec.WithAffinity(corev1.Affinity().WithPodAntiAffinity(anti))
+	}
+	for _, key := range p.Spread {
+		spec = spec.WithTopologySpreadConstraints(corev1.TopologySpreadConstraint().
+			WithMaxSkew(1).
+			WithTopologyKey(key).
+			WithWhenUnsatisfiable(v1.ScheduleAnyway).
+			WithLabelSelector(metav1.LabelSelector().WithMatchLabels(labels)),
+		)
+	}
+	return spec
+}
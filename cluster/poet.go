@@ -0,0 +1,50 @@
+package cluster
+
+import (
+	"strconv"
+	"time"
+)
+
+// PoetConfig is a configuration of the poet server.
+type PoetConfig struct {
+	// Duration of the poet round.
+	Duration time.Duration
+	// N is a difficulty of the proof.
+	N int
+	// Gateways is a number of bootnodes that are used as gateways. All bootnodes are used if zero.
+	Gateways int
+	// Args are appended to the poet command.
+	Args []string
+	// Image overwrites -poet-image.
+	Image string
+}
+
+// DefaultPoetConfig for the fastnet.
+func DefaultPoetConfig() PoetConfig {
+	return PoetConfig{
+		Duration: 30 * time.Second,
+		N:        10,
+	}
+}
+
+// WithPoetConfig modifies configuration of the poet server.
+func WithPoetConfig(modifier func(*PoetConfig)) Opt {
+	return func(c *Cluster) {
+		modifier(&c.poetConfig)
+	}
+}
+
+func (cfg *PoetConfig) args() []string {
+	args := []string{
+		"--duration=" + cfg.Duration.String(),
+		"--n=" + strconv.Itoa(cfg.N),
+	}
+	return append(args, cfg.Args...)
+}
+
+func (cfg *PoetConfig) image(global string) string {
+	if len(cfg.Image) > 0 {
+		return cfg.Image
+	}
+	return global
+}
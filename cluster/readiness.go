@@ -0,0 +1,259 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	spacemeshv1 "github.com/spacemeshos/api/release/go/spacemesh/v1"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	v1 "k8s.io/api/core/v1"
+	apimetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+
+	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+)
+
+// crashLoopRestarts is a number of restarts after which CrashLoopBackOff is considered
+// a failure. Lower number of restarts is expected after node was failed by chaos.
+const crashLoopRestarts = 3
+
+// fatalWaitingReasons are the reasons that won't be resolved without intervention.
+var fatalWaitingReasons = map[string]struct{}{
+	"ImagePullBackOff":           {},
+	"ErrImageNeverPull":          {},
+	"InvalidImageName":           {},
+	"CreateContainerConfigError": {},
+}
+
+var dialBackoff = wait.Backoff{
+	Duration: 500 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    10,
+	Cap:      10 * time.Second,
+}
+
+func podFailure(pod *v1.Pod) error {
+	if pod.Status.Phase == v1.PodFailed {
+		return fmt.Errorf("pod %s failed: %s %s", pod.Name, pod.Status.Reason, pod.Status.Message)
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		waiting := status.State.Waiting
+		if waiting == nil {
+			continue
+		}
+		_, fatal := fatalWaitingReasons[waiting.Reason]
+		if fatal || (waiting.Reason == "CrashLoopBackOff" && status.RestartCount >= crashLoopRestarts) {
+			return fmt.Errorf("container %s in pod %s is waiting with %s: %s",
+				status.Name, pod.Name, waiting.Reason, waiting.Message)
+		}
+	}
+	return nil
+}
+
+// podReady is true when all containers in the pod passed readiness probes.
+func podReady(pod *v1.Pod) bool {
+	if pod.Status.Phase != v1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func podSummary(pod *v1.Pod) string {
+	if pod == nil {
+		return "not created"
+	}
+	reasons := []string{string(pod.Status.Phase)}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil {
+			reasons = append(reasons, status.State.Waiting.Reason)
+		}
+	}
+	return strings.Join(reasons, "/")
+}
+
+// waitPods watches pods selected by labels until every pod from names is ready.
+// onReady is executed once for every pod as soon as it becomes ready.
+func waitPods(ctx context.Context, cctx *testcontext.Context, labels map[string]string,
+	names []string, onReady func(*v1.Pod)) error {
+	if len(names) == 0 {
+		return nil
+	}
+	pending := map[string]struct{}{}
+	for _, name := range names {
+		pending[name] = struct{}{}
+	}
+	last := map[string]*v1.Pod{}
+	lw := cache.NewFilteredListWatchFromClient(cctx.Client.CoreV1().RESTClient(), "pods", cctx.Namespace,
+		func(opts *apimetav1.ListOptions) {
+			opts.LabelSelector = k8slabels.SelectorFromSet(labels).String()
+		})
+	_, err := watchtools.UntilWithSync(ctx, lw, &v1.Pod{}, nil, func(event watch.Event) (bool, error) {
+		pod, ok := event.Object.(*v1.Pod)
+		if !ok || event.Type == watch.Deleted {
+			return false, nil
+		}
+		if _, exist := pending[pod.Name]; !exist {
+			return false, nil
+		}
+		last[pod.Name] = pod
+		if err := podFailure(pod); err != nil {
+			return false, err
+		}
+		if podReady(pod) {
+			delete(pending, pod.Name)
+			cctx.Log.Debugw("pod is ready",
+				"name", pod.Name,
+				"ip", pod.Status.PodIP,
+				"ready", len(names)-len(pending),
+				"total", len(names),
+			)
+			if onReady != nil {
+				onReady(pod)
+			}
+		}
+		return len(pending) == 0, nil
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		summary := []string{}
+		for name := range pending {
+			summary = append(summary, fmt.Sprintf("%s=%s", name, podSummary(last[name])))
+		}
+		sort.Strings(summary)
+		return fmt.Errorf("pods are not ready: %s", strings.Join(summary, ", "))
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// waitNodes waits for pods to be ready and connects to each of them concurrently.
+func waitNodes(cctx *testcontext.Context, labels map[string]string, names []string) ([]*NodeClient, error) {
+	ctx, cancel := context.WithCancel(cctx)
+	defer cancel()
+	eg, ctx := errgroup.WithContext(ctx)
+	index := map[string]int{}
+	for i, name := range names {
+		index[name] = i
+	}
+	clients := make([]*NodeClient, len(names))
+	err := waitPods(ctx, cctx, labels, names, func(pod *v1.Pod) {
+		i := index[pod.Name]
+		node := Node{
+			Name: pod.Name,
+			IP:   pod.Status.PodIP,
+			P2P:  7513,
+			GRPC: 9092,
+		}
+		eg.Go(func() error {
+			nc, err := connectNode(ctx, node)
+			if err != nil {
+				return err
+			}
+			nc.cctx = cctx
+			cctx.Log.Debugw("node is connected", "name", nc.Name, "id", nc.ID)
+			clients[i] = nc
+			return nil
+		})
+	})
+	if err != nil {
+		// watch is interrupted if one of the nodes failed to connect
+		interrupted := ctx.Err() != nil
+		cancel()
+		if werr := eg.Wait(); interrupted && werr != nil {
+			return nil, werr
+		}
+		return nil, err
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+// connectNode dials node with exponential backoff until it replies with network info.
+func connectNode(ctx context.Context, node Node) (*NodeClient, error) {
+	var (
+		nc      *NodeClient
+		lastErr error
+	)
+	err := wait.ExponentialBackoffWithContext(ctx, dialBackoff, func() (bool, error) {
+		nc, lastErr = dialNode(ctx, node)
+		return lastErr == nil, nil
+	})
+	if err != nil {
+		if lastErr != nil {
+			return nil, fmt.Errorf("connect to %s: %w", node.Name, lastErr)
+		}
+		return nil, fmt.Errorf("connect to %s: %w", node.Name, err)
+	}
+	return nc, nil
+}
+
+func dialNode(ctx context.Context, node Node) (*NodeClient, error) {
+	rctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(rctx, node.GRPCEndpoint(), grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		return nil, err
+	}
+	dbg := spacemeshv1.NewDebugServiceClient(conn)
+	info, err := dbg.NetworkInfo(rctx, &emptypb.Empty{})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	node.ID = info.Id
+	return &NodeClient{
+		Node:       node,
+		ClientConn: conn,
+	}, nil
+}
+
+// Resolve reads current ip of the node pod. Ip of the pod changes when pod is restarted.
+func (nc *NodeClient) Resolve(ctx context.Context) (Node, error) {
+	if nc.cctx == nil {
+		return Node{}, fmt.Errorf("node %s wasn't discovered in the cluster and can't be resolved", nc.Name)
+	}
+	pod, err := nc.cctx.Client.CoreV1().Pods(nc.cctx.Namespace).Get(ctx, nc.Name, apimetav1.GetOptions{})
+	if err != nil {
+		return Node{}, fmt.Errorf("read pod %s: %w", nc.Name, err)
+	}
+	if len(pod.Status.PodIP) == 0 {
+		return Node{}, fmt.Errorf("pod %s doesn't have ip: %s", nc.Name, podSummary(pod))
+	}
+	node := nc.Node
+	node.IP = pod.Status.PodIP
+	return node, nil
+}
+
+// Redial reads current ip of the node pod and connects to the node once.
+// Connection to the old ip never recovers after pod was restarted.
+// Returned client must be closed by the caller.
+func (nc *NodeClient) Redial(ctx context.Context) (*NodeClient, error) {
+	node, err := nc.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	redialed, err := dialNode(ctx, node)
+	if err != nil {
+		return nil, fmt.Errorf("redial %s: %w", nc.Name, err)
+	}
+	redialed.cctx = nc.cctx
+	return redialed, nil
+}
@@ -0,0 +1,164 @@
+package cluster
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	apimetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+)
+
+// Group of pods that share the same role in the cluster.
+type Group string
+
+const (
+	// BootGroup is a group for bootnodes.
+	BootGroup Group = bootnodesPrefix
+	// SmesherGroup is a group for regular smeshers.
+	SmesherGroup Group = smesherPrefix
+	// PoetGroup is a group for poet servers.
+	PoetGroup Group = poetSvc
+)
+
+// Resources describes compute and storage resources for every pod in the group.
+type Resources struct {
+	Requests v1.ResourceList
+	Limits   v1.ResourceList
+	// Storage is a size of the persistent volume. Ignored for poet.
+	Storage resource.Quantity
+	// StorageClass for the persistent volume. If empty default storage class of the k8s cluster is used.
+	StorageClass string
+	// GoMaxProcs is set as GOMAXPROCS env variable if not zero.
+	GoMaxProcs int
+}
+
+// DefaultResources that are used by every group unless configured otherwise.
+func DefaultResources() Resources {
+	return Resources{
+		Requests: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("0.5"),
+			v1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+		Storage:    resource.MustParse("1Gi"),
+		GoMaxProcs: 2,
+	}
+}
+
+// WithResources overwrites resources for pods in the group.
+// If Requests are not set they are equal to Limits, or taken from DefaultResources
+// if Limits are not set either. Storage that is not set is taken from DefaultResources.
+func WithResources(group Group, res Resources) Opt {
+	return func(c *Cluster) {
+		defaults := DefaultResources()
+		if len(res.Requests) == 0 && len(res.Limits) > 0 {
+			// requests above limits are rejected by k8s
+			res.Requests = res.Limits.DeepCopy()
+		} else if len(res.Requests) == 0 {
+			res.Requests = defaults.Requests
+		}
+		if res.Storage.IsZero() {
+			res.Storage = defaults.Storage
+		}
+		c.resources[group] = res
+	}
+}
+
+func (c *Cluster) groupResources(group Group) Resources {
+	if res, exist := c.resources[group]; exist {
+		return res
+	}
+	return DefaultResources()
+}
+
+// capacityResources are resources that are verified by capacityControl.
+var capacityResources = []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory}
+
+// capacityControl verifies that k8s nodes selected for the group have enough free
+// resources to run n more pods from the group. Free resources are allocatable resources
+// minus requests of the pods that are already scheduled on those nodes (including pods from other tests).
+// Pods that are pending and not yet scheduled are not accounted.
+func (c *Cluster) capacityControl(cctx *testcontext.Context, group Group, n int) error {
+	added := v1.ResourceList{}
+	requests := c.groupResources(group).Requests
+	for _, name := range capacityResources {
+		quantity, exist := requests[name]
+		if !exist {
+			continue
+		}
+		for i := 0; i < n; i++ {
+			total := added[name]
+			total.Add(quantity)
+			added[name] = total
+		}
+	}
+	free, err := freeCapacity(cctx, c.groupPlacement(group).nodeSelector(cctx.NodeSelector))
+	if err != nil {
+		return err
+	}
+	for name, quantity := range added {
+		// resource is missing if no nodes were selected or nodes don't report it
+		available := free[name]
+		if quantity.Cmp(available) > 0 {
+			return fmt.Errorf("not enough %s for %d pods in group %s: requested %s, free %s",
+				name, n, group, quantity.String(), available.String())
+		}
+	}
+	return nil
+}
+
+// freeCapacity sums allocatable resources on schedulable nodes that match node selector,
+// and subtracts requests of the running pods on those nodes.
+func freeCapacity(cctx *testcontext.Context, selector map[string]string) (v1.ResourceList, error) {
+	nodes, err := cctx.Client.CoreV1().Nodes().List(cctx, apimetav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(selector).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list k8s nodes: %w", err)
+	}
+	selected := map[string]struct{}{}
+	capacity := v1.ResourceList{}
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		selected[node.Name] = struct{}{}
+		for _, name := range capacityResources {
+			allocatable, exist := node.Status.Allocatable[name]
+			if !exist {
+				continue
+			}
+			total := capacity[name]
+			total.Add(allocatable)
+			capacity[name] = total
+		}
+	}
+	pods, err := cctx.Client.CoreV1().Pods("").List(cctx, apimetav1.ListOptions{
+		FieldSelector: "status.phase!=Succeeded,status.phase!=Failed",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+	for _, pod := range pods.Items {
+		if _, exist := selected[pod.Spec.NodeName]; !exist {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			for _, name := range capacityResources {
+				request, exist := container.Resources.Requests[name]
+				if !exist {
+					continue
+				}
+				total, exist := capacity[name]
+				if !exist {
+					continue
+				}
+				total.Sub(request)
+				capacity[name] = total
+			}
+		}
+	}
+	return capacity, nil
+}
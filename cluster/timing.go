@@ -0,0 +1,85 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Timing of the protocol, derived from the config and genesis time of the deployed cluster.
+// LayerDuration and LayersPerEpoch must not be zero, Timing returned by the Cluster is validated.
+type Timing struct {
+	Genesis        time.Time
+	LayerDuration  time.Duration
+	LayersPerEpoch uint32
+}
+
+// Timing returns protocol timing of the cluster. Returns an error if genesis time
+// is missing or malformed, or if layer duration or layers per epoch are zero.
+func (c *Cluster) Timing() (Timing, error) {
+	flag, exist := c.smesherFlags[GenesisTime(time.Time{}).Name]
+	if !exist {
+		return Timing{}, fmt.Errorf("genesis time is not set")
+	}
+	genesis, err := time.Parse(time.RFC3339, flag.Value)
+	if err != nil {
+		return Timing{}, fmt.Errorf("parse genesis time %q: %w", flag.Value, err)
+	}
+	timing := Timing{
+		Genesis:        genesis,
+		LayerDuration:  time.Duration(c.config.Main.LayerDuration),
+		LayersPerEpoch: c.config.Main.LayersPerEpoch,
+	}
+	if timing.LayerDuration <= 0 {
+		return Timing{}, fmt.Errorf("layer duration must be positive, got %s", timing.LayerDuration)
+	}
+	if timing.LayersPerEpoch == 0 {
+		return Timing{}, fmt.Errorf("layers per epoch must be positive")
+	}
+	return timing, nil
+}
+
+// EpochOf returns epoch of the layer.
+func (t Timing) EpochOf(layer uint32) uint32 {
+	return layer / t.LayersPerEpoch
+}
+
+// FirstLayer returns first layer in the epoch.
+func (t Timing) FirstLayer(epoch uint32) uint32 {
+	return epoch * t.LayersPerEpoch
+}
+
+// LastLayer returns last layer in the epoch.
+func (t Timing) LastLayer(epoch uint32) uint32 {
+	return t.FirstLayer(epoch+1) - 1
+}
+
+// LayerStart returns time when the layer starts.
+func (t Timing) LayerStart(layer uint32) time.Time {
+	return t.Genesis.Add(time.Duration(layer) * t.LayerDuration)
+}
+
+// CurrentLayer returns layer at the time.
+func (t Timing) CurrentLayer(now time.Time) uint32 {
+	if now.Before(t.Genesis) {
+		return 0
+	}
+	return uint32(now.Sub(t.Genesis) / t.LayerDuration)
+}
+
+// WaitLayer blocks until the layer starts.
+func (t Timing) WaitLayer(ctx context.Context, layer uint32) error {
+	timer := time.NewTimer(time.Until(t.LayerStart(layer)))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// WaitEpoch blocks until the epoch starts.
+func (t Timing) WaitEpoch(ctx context.Context, epoch uint32) error {
+	return t.WaitLayer(ctx, t.FirstLayer(epoch))
+}
@@ -0,0 +1,103 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimingLayers(t *testing.T) {
+	genesis := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	timing := Timing{Genesis: genesis, LayerDuration: 10 * time.Second, LayersPerEpoch: 4}
+
+	for _, tc := range []struct {
+		layer, epoch uint32
+	}{
+		{layer: 0, epoch: 0},
+		{layer: 3, epoch: 0},
+		{layer: 4, epoch: 1},
+		{layer: 11, epoch: 2},
+	} {
+		require.Equal(t, tc.epoch, timing.EpochOf(tc.layer), "layer %d", tc.layer)
+	}
+	require.Equal(t, uint32(0), timing.FirstLayer(0))
+	require.Equal(t, uint32(3), timing.LastLayer(0))
+	require.Equal(t, uint32(8), timing.FirstLayer(2))
+	require.Equal(t, uint32(11), timing.LastLayer(2))
+
+	require.Equal(t, genesis, timing.LayerStart(0))
+	require.Equal(t, genesis.Add(50*time.Second), timing.LayerStart(5))
+
+	require.Equal(t, uint32(0), timing.CurrentLayer(genesis.Add(-time.Hour)))
+	require.Equal(t, uint32(0), timing.CurrentLayer(genesis.Add(9*time.Second)))
+	require.Equal(t, uint32(1), timing.CurrentLayer(genesis.Add(10*time.Second)))
+	require.Equal(t, uint32(5), timing.CurrentLayer(genesis.Add(59*time.Second)))
+}
+
+func TestTimingWait(t *testing.T) {
+	timing := Timing{Genesis: time.Now(), LayerDuration: time.Hour, LayersPerEpoch: 4}
+	require.NoError(t, timing.WaitLayer(context.Background(), 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, timing.WaitEpoch(ctx, 1), context.DeadlineExceeded)
+}
+
+func TestClusterTiming(t *testing.T) {
+	genesis := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		desc   string
+		modify func(*Cluster)
+		err    bool
+	}{
+		{
+			desc:   "valid",
+			modify: func(c *Cluster) { c.addFlag(GenesisTime(genesis)) },
+		},
+		{
+			desc:   "missing genesis",
+			modify: func(c *Cluster) {},
+			err:    true,
+		},
+		{
+			desc: "malformed genesis",
+			modify: func(c *Cluster) {
+				c.addFlag(DeploymentFlag{Name: GenesisTime(genesis).Name, Value: "yesterday"})
+			},
+			err: true,
+		},
+		{
+			desc: "zero layers per epoch",
+			modify: func(c *Cluster) {
+				c.addFlag(GenesisTime(genesis))
+				c.config.Main.LayersPerEpoch = 0
+			},
+			err: true,
+		},
+		{
+			desc: "zero layer duration",
+			modify: func(c *Cluster) {
+				c.addFlag(GenesisTime(genesis))
+				c.config.Main.LayerDuration = 0
+			},
+			err: true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			c := newCluster()
+			tc.modify(c)
+			timing, err := c.Timing()
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, genesis.Equal(timing.Genesis))
+			require.Equal(t, time.Duration(c.config.Main.LayerDuration), timing.LayerDuration)
+			require.Equal(t, c.config.Main.LayersPerEpoch, timing.LayersPerEpoch)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	chaosv1alpha1 "github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+
+	chaoslib "github.com/spacemeshos/go-spacemesh/systest/chaos"
+	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+)
+
+// chaos injects chaos and keeps it until -duration expires or command is interrupted.
+func chaos(cctx *testcontext.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected one of: partition, fail, delay, list, clear")
+	}
+	switch args[0] {
+	case "list":
+		objects, err := chaoslib.List(cctx)
+		if err != nil {
+			return err
+		}
+		for _, obj := range objects {
+			kind := "NetworkChaos"
+			if _, ok := obj.(*chaosv1alpha1.PodChaos); ok {
+				kind = "PodChaos"
+			}
+			fmt.Printf("%s\t%s\n", kind, obj.GetName())
+		}
+		return nil
+	case "clear":
+		return chaoslib.ClearAll(cctx)
+	}
+	fs := flag.NewFlagSet("chaos "+args[0], flag.ExitOnError)
+	name := fs.String("name", args[0], "name of the chaos object")
+	duration := fs.Duration("duration", 0, "chaos is removed after duration. if zero it is removed on interrupt")
+	var inject func() (error, chaoslib.Teardown)
+	switch args[0] {
+	case "partition":
+		a := fs.String("a", "", "comma separated pods on one side of the partition")
+		b := fs.String("b", "", "comma separated pods on the other side of the partition")
+		inject = func() (error, chaoslib.Teardown) {
+			return chaoslib.Partition2(cctx, *name, splitPods(*a), splitPods(*b))
+		}
+	case "fail":
+		selector := selectorFlags(fs)
+		inject = func() (error, chaoslib.Teardown) {
+			selected, err := selector()
+			if err != nil {
+				return err, nil
+			}
+			return chaoslib.FailSelected(cctx, *name, selected)
+		}
+	case "delay":
+		selector := selectorFlags(fs)
+		latency := fs.Duration("latency", 100*time.Millisecond, "added latency")
+		jitter := fs.Duration("jitter", 0, "jitter of the added latency")
+		inject = func() (error, chaoslib.Teardown) {
+			selected, err := selector()
+			if err != nil {
+				return err, nil
+			}
+			return chaoslib.DelaySelected(cctx, *name, *latency, *jitter, selected)
+		}
+	default:
+		return fmt.Errorf("unknown chaos %q. expected one of: partition, fail, delay, list, clear", args[0])
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	err, teardown := inject()
+	if err != nil {
+		return err
+	}
+	cctx.Log.Infow("chaos injected", "name", *name, "duration", *duration)
+	var timeout <-chan time.Time
+	if *duration > 0 {
+		timeout = time.After(*duration)
+	}
+	select {
+	case <-cctx.Done():
+	case <-timeout:
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), chaoslib.TeardownTimeout)
+	defer cancel()
+	if err := teardown(ctx); err != nil {
+		return fmt.Errorf("teardown %s: %w", *name, err)
+	}
+	cctx.Log.Infow("chaos removed", "name", *name)
+	return nil
+}
+
+// selectorFlags registers flags for selecting pods either by name or by app label.
+// Returned function must be called after flags are parsed, it fails if neither -pods nor -app is set.
+func selectorFlags(fs *flag.FlagSet) func() (chaoslib.Selector, error) {
+	pods := fs.String("pods", "", "comma separated pods")
+	app := fs.String("app", "", "select pods by app label (boot, smesher or poet) instead of names")
+	random := fs.Int("random", 0, "if positive selects random number of matched pods")
+	percent := fs.Int("percent", 0, "if positive selects percent of matched pods")
+	return func() (chaoslib.Selector, error) {
+		if len(*pods) == 0 && len(*app) == 0 {
+			return chaoslib.Selector{}, fmt.Errorf("either -pods or -app is required")
+		}
+		selector := chaoslib.Pods(splitPods(*pods)...)
+		if len(*app) > 0 {
+			selector = chaoslib.App(*app)
+		}
+		if *random > 0 {
+			selector = selector.Random(*random)
+		} else if *percent > 0 {
+			selector = selector.Percent(*percent)
+		}
+		return selector, nil
+	}
+}
+
+func splitPods(pods string) []string {
+	if len(pods) == 0 {
+		return nil
+	}
+	return strings.Split(pods, ",")
+}
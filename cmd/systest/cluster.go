@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	apimetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spacemeshos/go-spacemesh/systest/cluster"
+	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+)
+
+func up(cctx *testcontext.Context, args []string) error {
+	fs := flag.NewFlagSet("up", flag.ExitOnError)
+	bootnodes := fs.Int("bootnodes", 2, "number of bootnodes")
+	smeshers := fs.Int("smeshers", 8, "number of smeshers")
+	poet := fs.Bool("poet", true, "if true poet server will be deployed")
+	keys := fs.Int("keys", 10, "number of accounts with balance in genesis")
+	metrics := fs.Bool("metrics", false, "if true metrics will be exposed on every smesher")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if _, err := cluster.LoadMetadata(cctx); err == nil {
+		return fmt.Errorf("cluster is already deployed in namespace %s", cctx.Namespace)
+	}
+	cctx.ClusterSize = *bootnodes + *smeshers
+	if err := cctx.Create(); err != nil {
+		return err
+	}
+	opts := []cluster.Opt{cluster.WithKeys(*keys)}
+	if *metrics {
+		opts = append(opts, cluster.WithMetrics())
+	}
+	cl := cluster.New(cctx, opts...)
+	if err := cl.AddBootnodes(cctx, *bootnodes); err != nil {
+		return err
+	}
+	if *poet {
+		if err := cl.AddPoet(cctx); err != nil {
+			return err
+		}
+	}
+	if *smeshers > 0 {
+		if err := cl.AddSmeshers(cctx, *smeshers); err != nil {
+			return err
+		}
+	}
+	cctx.Log.Infow("cluster is up", "namespace", cctx.Namespace, "nodes", cl.Total())
+	return nil
+}
+
+func down(cctx *testcontext.Context, args []string) error {
+	fs := flag.NewFlagSet("down", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return cctx.Delete()
+}
+
+func sweep(cctx *testcontext.Context, args []string) error {
+	fs := flag.NewFlagSet("sweep", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	deleted, err := cctx.Sweep()
+	for _, ns := range deleted {
+		fmt.Println(ns)
+	}
+	return err
+}
+
+func scale(cctx *testcontext.Context, args []string) error {
+	fs := flag.NewFlagSet("scale", flag.ExitOnError)
+	smeshers := fs.Int("smeshers", 0, "total number of smeshers after scaling")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	meta, err := cluster.LoadMetadata(cctx)
+	if err != nil {
+		return err
+	}
+	switch {
+	case *smeshers == meta.Smeshers:
+		return nil
+	case *smeshers < meta.Smeshers:
+		return fmt.Errorf("scaling down is not supported (%d smeshers deployed)", meta.Smeshers)
+	}
+	cctx.ClusterSize = meta.Bootnodes + *smeshers
+	cl, err := cluster.Discover(cctx)
+	if err != nil {
+		return err
+	}
+	return cl.AddSmeshers(cctx, *smeshers-meta.Smeshers)
+}
+
+func status(cctx *testcontext.Context, args []string) error {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	meta, err := cluster.LoadMetadata(cctx)
+	if errors.Is(err, cluster.ErrNotDeployed) {
+		fmt.Printf("cluster is not deployed in namespace %s\n", cctx.Namespace)
+	} else if err != nil {
+		return err
+	} else {
+		fmt.Printf("namespace:    %s\n", cctx.Namespace)
+		fmt.Printf("image:        %s\n", meta.Image)
+		fmt.Printf("poet image:   %s\n", meta.PoetImage)
+		fmt.Printf("genesis time: %s\n", meta.GenesisTime)
+		fmt.Printf("network id:   %d\n", meta.NetworkID)
+		fmt.Printf("bootnodes:    %d\n", meta.Bootnodes)
+		fmt.Printf("smeshers:     %d\n", meta.Smeshers)
+		fmt.Printf("poets:        %s\n", strings.Join(meta.Poets, ","))
+		fmt.Println()
+	}
+	pods, err := cctx.Client.CoreV1().Pods(cctx.Namespace).List(cctx, apimetav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list pods: %w", err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPHASE\tREADY\tRESTARTS\tIP\tNODE")
+	for _, pod := range pods.Items {
+		ready, restarts := 0, int32(0)
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Ready {
+				ready++
+			}
+			restarts += status.RestartCount
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d/%d\t%d\t%s\t%s\n", pod.Name, pod.Status.Phase,
+			ready, len(pod.Spec.Containers), restarts, pod.Status.PodIP, pod.Spec.NodeName)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/spacemeshos/go-spacemesh/systest/cluster"
+	logslib "github.com/spacemeshos/go-spacemesh/systest/logs"
+	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+)
+
+func logs(cctx *testcontext.Context, args []string) error {
+	fs := flag.NewFlagSet("logs", flag.ExitOnError)
+	pod := fs.String("pod", "", "name of the pod")
+	container := fs.String("container", "smesher", "name of the container")
+	follow := fs.Bool("follow", false, "if true waits for new entries")
+	since := fs.Duration("since", 0, "print only entries that were logged within duration")
+	level := zapcore.DebugLevel
+	fs.Var(&level, "level", "print only entries with level or above")
+	module := fs.String("module", "", "print only entries from the module")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(*pod) == 0 {
+		return fmt.Errorf("-pod must be set")
+	}
+	opts := []logslib.Opt{logslib.Container(*container)}
+	if *follow {
+		opts = append(opts, logslib.Follow())
+	}
+	if *since > 0 {
+		opts = append(opts, logslib.Since(time.Now().Add(-*since)))
+	}
+	filter := logslib.Level(level)
+	if len(*module) > 0 {
+		filter = logslib.All(filter, logslib.Module(*module))
+	}
+	node := &cluster.NodeClient{Node: cluster.Node{Name: *pod}}
+	err := logslib.Watch(cctx, cctx, node, func(entry *logslib.Entry) (bool, error) {
+		if filter(entry) {
+			fmt.Println(entry.String())
+		}
+		return true, nil
+	}, opts...)
+	if cctx.Err() != nil {
+		// interrupted while following
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+// Command systest manages spacemesh cluster outside of the tests.
+//
+// Global flags are shared with the tests (see testcontext) and must be passed
+// before the subcommand, for example:
+//
+//	systest -namespace=dev -size=10 up
+//	systest -namespace=dev chaos partition -name=split -a=smesher-0,smesher-1 -b=smesher-2
+//	systest -namespace=dev down
+//
+// Smeshers are dialed over grpc, therefore up, scale and chaos subcommands
+// must run from where pod ips are routable (for example inside the k8s cluster).
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+)
+
+type command struct {
+	name  string
+	usage string
+	// namespaced commands require -namespace.
+	namespaced bool
+	run        func(*testcontext.Context, []string) error
+}
+
+var commands = []command{
+	{"up", "deploy bootnodes, poet and smeshers", true, up},
+	{"down", "delete namespace with the cluster", true, down},
+	{"scale", "add smeshers to the deployed cluster", true, scale},
+	{"chaos", "inject chaos (partition, fail, delay), list or clear it", true, chaos},
+	{"status", "print metadata and pods of the cluster", true, status},
+	{"logs", "print logs of the pod", true, logs},
+	{"sweep", "delete test namespaces with expired ttl", false, sweep},
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "usage: %s [flags] <command> [command flags]\n\ncommands:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-8s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintf(out, "\nflags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	var selected *command
+	for i := range commands {
+		if commands[i].name == flag.Arg(0) {
+			selected = &commands[i]
+		}
+	}
+	if selected == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	cctx, err := testcontext.Standalone(ctx)
+	if err == nil && selected.namespaced && len(cctx.Namespace) == 0 {
+		err = errors.New("-namespace must be set")
+	}
+	if err == nil {
+		err = selected.run(cctx, flag.Args()[1:])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", selected.name, err)
+		os.Exit(1)
+	}
+}
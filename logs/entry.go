@@ -0,0 +1,120 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Keys used by go-spacemesh json encoder.
+const (
+	timeKey       = "T"
+	levelKey      = "L"
+	nameKey       = "N"
+	callerKey     = "C"
+	messageKey    = "M"
+	stacktraceKey = "S"
+)
+
+var timeLayouts = []string{
+	"2006-01-02T15:04:05.000Z0700",
+	time.RFC3339Nano,
+}
+
+// Entry is a parsed json log entry.
+type Entry struct {
+	Time  time.Time
+	Level zapcore.Level
+	// Name of the logger, it is prefixed with the short node id.
+	Name string
+	// Module is a name of the logger without node id, e.g. hare or tortoise.
+	Module     string
+	Message    string
+	Caller     string
+	Stacktrace string
+	// Fields are all other fields from the entry.
+	Fields map[string]interface{}
+}
+
+// String returns human readable representation of the entry.
+func (e *Entry) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%s %s %s %s", e.Time.Format(time.RFC3339Nano), e.Level.CapitalString(), e.Name, e.Message)
+	if len(e.Fields) > 0 {
+		fields, _ := json.Marshal(e.Fields)
+		buf.WriteRune(' ')
+		buf.Write(fields)
+	}
+	return buf.String()
+}
+
+// Field returns string representation of the field.
+func (e *Entry) Field(name string) (string, bool) {
+	value, exist := e.Fields[name]
+	if !exist {
+		return "", false
+	}
+	if s, ok := value.(string); ok {
+		return s, true
+	}
+	return fmt.Sprint(value), true
+}
+
+// Parse parses a single json line emitted by go-spacemesh.
+func Parse(line []byte) (*Entry, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] != '{' {
+		return nil, fmt.Errorf("not a json entry: %q", line)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	fields := map[string]interface{}{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("decode entry %q: %w", line, err)
+	}
+	entry := &Entry{Fields: fields}
+	if raw, ok := pop(fields, levelKey); ok {
+		if err := entry.Level.UnmarshalText([]byte(raw)); err != nil {
+			return nil, fmt.Errorf("parse level %q: %w", raw, err)
+		}
+	}
+	if raw, ok := pop(fields, timeKey); ok {
+		parsed, err := parseTime(raw)
+		if err != nil {
+			return nil, err
+		}
+		entry.Time = parsed
+	}
+	entry.Name, _ = pop(fields, nameKey)
+	entry.Module = entry.Name
+	if i := strings.IndexRune(entry.Name, '.'); i >= 0 {
+		entry.Module = entry.Name[i+1:]
+	}
+	entry.Message, _ = pop(fields, messageKey)
+	entry.Caller, _ = pop(fields, callerKey)
+	entry.Stacktrace, _ = pop(fields, stacktraceKey)
+	return entry, nil
+}
+
+func pop(fields map[string]interface{}, key string) (string, bool) {
+	value, exist := fields[key]
+	if !exist {
+		return "", false
+	}
+	delete(fields, key)
+	s, ok := value.(string)
+	return s, ok
+}
+
+func parseTime(raw string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if parsed, err := time.Parse(layout, raw); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown time format %q", raw)
+}
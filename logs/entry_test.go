@@ -0,0 +1,79 @@
+package logs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		desc     string
+		line     string
+		expected *Entry
+		err      bool
+	}{
+		{
+			desc: "full entry",
+			line: `{"L":"WARN","T":"2022-02-01T10:20:30.123+0000","N":"a1b2c.hare","M":"late message","C":"hare/broker.go:10","layer_id":12,"peer":"x"}`,
+			expected: &Entry{
+				Time:    time.Date(2022, 2, 1, 10, 20, 30, 123000000, time.UTC),
+				Level:   zapcore.WarnLevel,
+				Name:    "a1b2c.hare",
+				Module:  "hare",
+				Message: "late message",
+				Caller:  "hare/broker.go:10",
+				Fields:  map[string]interface{}{"layer_id": "12", "peer": "x"},
+			},
+		},
+		{
+			desc: "rfc3339 time and nested module",
+			line: `  {"L":"ERROR","T":"2022-02-01T10:20:30.5Z","N":"a1b2c.tortoise.verifying","M":"failed","S":"trace"}  `,
+			expected: &Entry{
+				Time:       time.Date(2022, 2, 1, 10, 20, 30, 500000000, time.UTC),
+				Level:      zapcore.ErrorLevel,
+				Name:       "a1b2c.tortoise.verifying",
+				Module:     "tortoise.verifying",
+				Message:    "failed",
+				Stacktrace: "trace",
+				Fields:     map[string]interface{}{},
+			},
+		},
+		{
+			desc: "name without node id",
+			line: `{"N":"app","M":"started"}`,
+			expected: &Entry{
+				Name:    "app",
+				Module:  "app",
+				Message: "started",
+				Fields:  map[string]interface{}{},
+			},
+		},
+		{desc: "empty", line: "", err: true},
+		{desc: "not json", line: "panic: runtime error", err: true},
+		{desc: "malformed json", line: `{"L":"INFO"`, err: true},
+		{desc: "unknown level", line: `{"L":"LOUD","M":"x"}`, err: true},
+		{desc: "unknown time format", line: `{"T":"yesterday","M":"x"}`, err: true},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			entry, err := Parse([]byte(tc.line))
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, tc.expected.Time.Equal(entry.Time), "time %s != %s", tc.expected.Time, entry.Time)
+			entry.Time = tc.expected.Time
+			// numbers are decoded as json.Number, compare string representation of the fields
+			fields := map[string]interface{}{}
+			for key := range entry.Fields {
+				fields[key], _ = entry.Field(key)
+			}
+			entry.Fields = fields
+			require.Equal(t, tc.expected, entry)
+		})
+	}
+}
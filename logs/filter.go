@@ -0,0 +1,70 @@
+package logs
+
+import (
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Filter selects log entries.
+type Filter func(*Entry) bool
+
+// Level selects entries with level equal or higher than min.
+func Level(min zapcore.Level) Filter {
+	return func(e *Entry) bool {
+		return e.Level >= min
+	}
+}
+
+// Module selects entries from the module or any of its submodules.
+func Module(module string) Filter {
+	return func(e *Entry) bool {
+		return e.Module == module || strings.HasPrefix(e.Module, module+".")
+	}
+}
+
+// Message selects entries with message that contains substr.
+func Message(substr string) Filter {
+	return func(e *Entry) bool {
+		return strings.Contains(e.Message, substr)
+	}
+}
+
+// Field selects entries with field equal to the value.
+func Field(name, value string) Filter {
+	return func(e *Entry) bool {
+		actual, exist := e.Field(name)
+		return exist && actual == value
+	}
+}
+
+// All selects entries that match every filter.
+func All(filters ...Filter) Filter {
+	return func(e *Entry) bool {
+		for _, filter := range filters {
+			if !filter(e) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Any selects entries that match at least one filter.
+func Any(filters ...Filter) Filter {
+	return func(e *Entry) bool {
+		for _, filter := range filters {
+			if filter(e) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not selects entries that don't match filter.
+func Not(filter Filter) Filter {
+	return func(e *Entry) bool {
+		return !filter(e)
+	}
+}
@@ -0,0 +1,148 @@
+package logs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apimetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spacemeshos/go-spacemesh/systest/cluster"
+	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+)
+
+const (
+	smesherContainer = "smesher"
+	maxLineSize      = 1 << 20
+)
+
+// Opt is for configuring log stream.
+type Opt func(*options)
+
+type options struct {
+	container string
+	follow    bool
+	since     *apimetav1.Time
+}
+
+// Follow keeps stream open and waits for new entries.
+func Follow() Opt {
+	return func(o *options) {
+		o.follow = true
+	}
+}
+
+// Since skips entries that were logged before the timestamp.
+func Since(timestamp time.Time) Opt {
+	return func(o *options) {
+		t := apimetav1.NewTime(timestamp)
+		o.since = &t
+	}
+}
+
+// Container overwrites container name. By default logs are streamed from smesher container.
+func Container(name string) Opt {
+	return func(o *options) {
+		o.container = name
+	}
+}
+
+// Watch streams logs of the node pod and executes collector for every parsed json entry,
+// until collector returns false or an error. Lines that are not json are ignored.
+func Watch(ctx context.Context, cctx *testcontext.Context, node *cluster.NodeClient,
+	collector func(*Entry) (bool, error), opts ...Opt) error {
+	o := options{container: smesherContainer}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	stream, err := cctx.Client.CoreV1().Pods(cctx.Namespace).GetLogs(node.Name, &v1.PodLogOptions{
+		Container: o.container,
+		Follow:    o.follow,
+		SinceTime: o.since,
+	}).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("stream logs from %s: %w", node.Name, err)
+	}
+	defer stream.Close()
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+	for scanner.Scan() {
+		entry, err := Parse(scanner.Bytes())
+		if err != nil {
+			continue
+		}
+		cont, err := collector(entry)
+		if err != nil {
+			return err
+		}
+		if !cont {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read logs from %s: %w", node.Name, err)
+	}
+	return nil
+}
+
+// Collect returns entries that match filter from logs that are available at the moment.
+func Collect(ctx context.Context, cctx *testcontext.Context, node *cluster.NodeClient,
+	filter Filter, opts ...Opt) ([]*Entry, error) {
+	var rst []*Entry
+	err := Watch(ctx, cctx, node, func(entry *Entry) (bool, error) {
+		if filter(entry) {
+			rst = append(rst, entry)
+		}
+		return true, nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return rst, nil
+}
+
+// None returns an error with matched entries if the node logged any entry that matches filter.
+func None(ctx context.Context, cctx *testcontext.Context, node *cluster.NodeClient,
+	filter Filter, opts ...Opt) error {
+	matched, err := Collect(ctx, cctx, node, filter, opts...)
+	if err != nil {
+		return err
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	const limit = 10
+	lines := []string{}
+	for i, entry := range matched {
+		if i == limit {
+			lines = append(lines, fmt.Sprintf("... %d more", len(matched)-limit))
+			break
+		}
+		lines = append(lines, entry.String())
+	}
+	return fmt.Errorf("node %s logged %d unexpected entries:\n%s",
+		node.Name, len(matched), strings.Join(lines, "\n"))
+}
+
+// WaitFor follows logs of the node until it logs entry that matches filter.
+func WaitFor(ctx context.Context, cctx *testcontext.Context, node *cluster.NodeClient,
+	filter Filter, opts ...Opt) (*Entry, error) {
+	var found *Entry
+	err := Watch(ctx, cctx, node, func(entry *Entry) (bool, error) {
+		if filter(entry) {
+			found = entry
+			return false, nil
+		}
+		return true, nil
+	}, append(opts, Follow())...)
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("log stream from %s closed before entry was found", node.Name)
+	}
+	return found, nil
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func (c *Collector) check(name string, violated func(node string, series []Sample) string) error {
+	nodes := c.allNodes()
+	sort.Strings(nodes)
+	var failures []string
+	for _, node := range nodes {
+		if !c.scraped(node) {
+			failures = append(failures, fmt.Sprintf("%s: no samples were scraped", node))
+			continue
+		}
+		series := c.Series(node, name, nil)
+		if len(series) == 0 {
+			failures = append(failures, fmt.Sprintf("%s: metric %s is missing", node, name))
+			continue
+		}
+		if reason := violated(node, series); len(reason) > 0 {
+			failures = append(failures, fmt.Sprintf("%s: %s", node, reason))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("metric %s assertion failed:\n%s", name, strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// AtLeast verifies that the last value of the metric is at least min on every node.
+func (c *Collector) AtLeast(name string, min float64) error {
+	return c.check(name, func(node string, series []Sample) string {
+		last := series[len(series)-1]
+		if last.Value < min {
+			return fmt.Sprintf("%v at %s is lower than %v", last.Value, last.Time.Format("15:04:05"), min)
+		}
+		return ""
+	})
+}
+
+// AtMost verifies that the last value of the metric is at most max on every node.
+func (c *Collector) AtMost(name string, max float64) error {
+	return c.check(name, func(node string, series []Sample) string {
+		last := series[len(series)-1]
+		if last.Value > max {
+			return fmt.Sprintf("%v at %s is higher than %v", last.Value, last.Time.Format("15:04:05"), max)
+		}
+		return ""
+	})
+}
+
+// MaxGrowth verifies that the last value of the metric didn't grow more than ratio
+// compared to the first value on every node. For example 0.2 allows 20% growth.
+// If the first value is not positive, ratio is undefined and any growth is a violation.
+func (c *Collector) MaxGrowth(name string, ratio float64) error {
+	return c.check(name, func(node string, series []Sample) string {
+		first, last := series[0], series[len(series)-1]
+		if first.Value <= 0 {
+			if last.Value > first.Value {
+				return fmt.Sprintf("grew from %v to %v", first.Value, last.Value)
+			}
+			return ""
+		}
+		growth := last.Value/first.Value - 1
+		if growth > ratio {
+			return fmt.Sprintf("grew by %.1f%% from %v to %v (max %.1f%%)",
+				growth*100, first.Value, last.Value, ratio*100)
+		}
+		return ""
+	})
+}
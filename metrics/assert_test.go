@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func sampleSnapshot(at time.Time, name string, value float64) snapshot {
+	return snapshot{
+		time: at,
+		families: map[string]*dto.MetricFamily{
+			name: {
+				Name:   proto.String(name),
+				Type:   dto.MetricType_GAUGE.Enum(),
+				Metric: []*dto.Metric{{Gauge: &dto.Gauge{Value: proto.Float64(value)}}},
+			},
+		},
+	}
+}
+
+func TestAssertions(t *testing.T) {
+	const name = "value"
+	for _, tc := range []struct {
+		desc   string
+		series map[string][]float64
+		// nodes that were passed to Run
+		expected []string
+		assert   func(*Collector) error
+		err      bool
+	}{
+		{
+			desc:   "at least",
+			series: map[string][]float64{"a": {1, 5}, "b": {7}},
+			assert: func(c *Collector) error { return c.AtLeast(name, 5) },
+		},
+		{
+			desc:   "at least violated by last value",
+			series: map[string][]float64{"a": {5, 4}, "b": {7}},
+			assert: func(c *Collector) error { return c.AtLeast(name, 5) },
+			err:    true,
+		},
+		{
+			desc:   "at most",
+			series: map[string][]float64{"a": {10, 3}},
+			assert: func(c *Collector) error { return c.AtMost(name, 3) },
+		},
+		{
+			desc:   "at most violated",
+			series: map[string][]float64{"a": {1, 4}},
+			assert: func(c *Collector) error { return c.AtMost(name, 3) },
+			err:    true,
+		},
+		{
+			desc:   "max growth",
+			series: map[string][]float64{"a": {100, 150, 120}},
+			assert: func(c *Collector) error { return c.MaxGrowth(name, 0.2) },
+		},
+		{
+			desc:   "max growth violated",
+			series: map[string][]float64{"a": {100, 150}},
+			assert: func(c *Collector) error { return c.MaxGrowth(name, 0.2) },
+			err:    true,
+		},
+		{
+			desc:   "growth from zero",
+			series: map[string][]float64{"a": {0, 1}},
+			assert: func(c *Collector) error { return c.MaxGrowth(name, 0.2) },
+			err:    true,
+		},
+		{
+			desc:   "zero without growth",
+			series: map[string][]float64{"a": {0, 0}},
+			assert: func(c *Collector) error { return c.MaxGrowth(name, 0.2) },
+		},
+		{
+			desc:     "node never scraped",
+			series:   map[string][]float64{"a": {1}},
+			expected: []string{"a", "b"},
+			assert:   func(c *Collector) error { return c.AtLeast(name, 0) },
+			err:      true,
+		},
+		{
+			desc:   "metric missing",
+			series: map[string][]float64{"a": {1}},
+			assert: func(c *Collector) error { return c.AtLeast("other", 0) },
+			err:    true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			c := New(time.Second)
+			for _, node := range tc.expected {
+				c.expected[node] = struct{}{}
+			}
+			start := time.Now()
+			for node, values := range tc.series {
+				for i, value := range values {
+					c.add(node, sampleSnapshot(start.Add(time.Duration(i)*time.Second), name, value))
+				}
+			}
+			err := tc.assert(c)
+			if tc.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
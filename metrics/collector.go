@@ -0,0 +1,244 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/spacemeshos/go-spacemesh/systest/cluster"
+)
+
+const (
+	// Goroutines is a number of goroutines in the go runtime.
+	Goroutines = "go_goroutines"
+	// HeapInuse is a number of heap bytes that are in use.
+	HeapInuse = "go_memstats_heap_inuse_bytes"
+	// Peers is a number of connected peers.
+	Peers = "spacemesh_p2p_peers"
+)
+
+// Sample is a value of the metric at the time of scrape.
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+type snapshot struct {
+	time     time.Time
+	families map[string]*dto.MetricFamily
+}
+
+// Opt is for configuring collector.
+type Opt func(*Collector)
+
+// WithLimit overwrites the maximal number of snapshots that are kept for every node.
+// The first snapshot is always kept, and the oldest of the others is dropped once the limit is reached.
+func WithLimit(limit int) Opt {
+	return func(c *Collector) {
+		c.limit = limit
+	}
+}
+
+// Collector periodically scrapes metrics from every node and stores them in memory.
+type Collector struct {
+	interval time.Duration
+	limit    int
+	client   *http.Client
+
+	mu sync.Mutex
+	// expected are names of the nodes that were passed to Run.
+	expected map[string]struct{}
+	// endpoints are metrics endpoints of the nodes that were resolved after scrape failed.
+	endpoints map[string]string
+	snapshots map[string][]snapshot
+}
+
+// New creates collector that will scrape nodes with the interval.
+func New(interval time.Duration, opts ...Opt) *Collector {
+	c := &Collector{
+		interval:  interval,
+		limit:     1000,
+		client:    &http.Client{Timeout: interval},
+		expected:  map[string]struct{}{},
+		endpoints: map[string]string{},
+		snapshots: map[string][]snapshot{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Run scrapes metrics from nodes until context is canceled.
+// Scrape errors are not fatal, they are expected if node was failed by chaos.
+// However every node is expected to be scraped at least once, otherwise assertions will fail.
+func (c *Collector) Run(ctx context.Context, nodes ...*cluster.NodeClient) error {
+	c.mu.Lock()
+	for _, node := range nodes {
+		c.expected[node.Name] = struct{}{}
+	}
+	c.mu.Unlock()
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		var eg errgroup.Group
+		for _, node := range nodes {
+			node := node
+			eg.Go(func() error {
+				_ = c.Scrape(ctx, node)
+				return nil
+			})
+		}
+		_ = eg.Wait()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scrape metrics from the node once and store them.
+// If scrape failed, current ip of the node pod is resolved and used for the next scrape,
+// as ip changes when pod is restarted.
+func (c *Collector) Scrape(ctx context.Context, node *cluster.NodeClient) error {
+	c.mu.Lock()
+	endpoint, exist := c.endpoints[node.Name]
+	c.mu.Unlock()
+	if !exist {
+		endpoint = node.MetricsEndpoint()
+	}
+	err := c.scrape(ctx, node.Name, endpoint)
+	if err == nil {
+		return nil
+	}
+	if resolved, rerr := node.Resolve(ctx); rerr == nil {
+		c.mu.Lock()
+		c.endpoints[node.Name] = resolved.MetricsEndpoint()
+		c.mu.Unlock()
+	}
+	return err
+}
+
+func (c *Collector) scrape(ctx context.Context, name, endpoint string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("scrape %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("scrape %s: unexpected status %s", name, resp.Status)
+	}
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return fmt.Errorf("parse metrics from %s: %w", name, err)
+	}
+	c.add(name, snapshot{time: time.Now(), families: families})
+	return nil
+}
+
+func (c *Collector) add(name string, snap snapshot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshots := c.snapshots[name]
+	if c.limit > 1 && len(snapshots) >= c.limit {
+		// the first snapshot is kept as a baseline for growth assertions
+		snapshots = append(snapshots[:1], snapshots[2:]...)
+	}
+	c.snapshots[name] = append(snapshots, snap)
+}
+
+// Nodes returns names of the nodes that were scraped at least once.
+func (c *Collector) Nodes() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var rst []string
+	for name := range c.snapshots {
+		rst = append(rst, name)
+	}
+	return rst
+}
+
+// allNodes returns names of the nodes that were passed to Run or scraped at least once.
+func (c *Collector) allNodes() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var rst []string
+	for name := range c.expected {
+		rst = append(rst, name)
+	}
+	for name := range c.snapshots {
+		if _, exist := c.expected[name]; !exist {
+			rst = append(rst, name)
+		}
+	}
+	return rst
+}
+
+func (c *Collector) scraped(node string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.snapshots[node]) > 0
+}
+
+// Series returns all samples of the metric collected from the node.
+// Values of the metrics with the same name, but different labels, are summed.
+func (c *Collector) Series(node, name string, labels map[string]string) []Sample {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var rst []Sample
+	for _, snap := range c.snapshots[node] {
+		family, exist := snap.families[name]
+		if !exist {
+			continue
+		}
+		rst = append(rst, Sample{Time: snap.time, Value: familyValue(family, labels)})
+	}
+	return rst
+}
+
+func familyValue(family *dto.MetricFamily, labels map[string]string) float64 {
+	var total float64
+	for _, metric := range family.Metric {
+		if !matchLabels(metric, labels) {
+			continue
+		}
+		switch {
+		case metric.Gauge != nil:
+			total += metric.Gauge.GetValue()
+		case metric.Counter != nil:
+			total += metric.Counter.GetValue()
+		case metric.Untyped != nil:
+			total += metric.Untyped.GetValue()
+		case metric.Summary != nil:
+			total += metric.Summary.GetSampleSum()
+		case metric.Histogram != nil:
+			total += metric.Histogram.GetSampleSum()
+		}
+	}
+	return total
+}
+
+func matchLabels(metric *dto.Metric, labels map[string]string) bool {
+	matched := 0
+	for _, pair := range metric.Label {
+		if value, exist := labels[pair.GetName()]; exist {
+			if value != pair.GetValue() {
+				return false
+			}
+			matched++
+		}
+	}
+	return matched == len(labels)
+}
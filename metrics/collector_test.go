@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const exposition = `# HELP go_goroutines Number of goroutines that currently exist.
+# TYPE go_goroutines gauge
+go_goroutines 42
+# HELP spacemesh_p2p_peers Number of peers.
+# TYPE spacemesh_p2p_peers gauge
+spacemesh_p2p_peers{protocol="gossip"} 5
+spacemesh_p2p_peers{protocol="sync"} 3
+# HELP requests_total Total requests.
+# TYPE requests_total counter
+requests_total{code="200"} 10
+requests_total{code="500"} 2
+# HELP latency_seconds Request latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="1"} 3
+latency_seconds_bucket{le="+Inf"} 4
+latency_seconds_sum 2.5
+latency_seconds_count 4
+`
+
+func TestScrape(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(exposition))
+	}))
+	defer srv.Close()
+
+	c := New(time.Second)
+	require.NoError(t, c.scrape(context.Background(), "smesher-0", srv.URL))
+	require.Equal(t, []string{"smesher-0"}, c.Nodes())
+
+	for _, tc := range []struct {
+		desc   string
+		name   string
+		labels map[string]string
+		value  float64
+	}{
+		{desc: "gauge", name: Goroutines, value: 42},
+		{desc: "sum over labels", name: Peers, value: 8},
+		{desc: "filter by label", name: Peers, labels: map[string]string{"protocol": "sync"}, value: 3},
+		{desc: "counter", name: "requests_total", labels: map[string]string{"code": "500"}, value: 2},
+		{desc: "histogram sum", name: "latency_seconds", value: 2.5},
+		{desc: "no matching labels", name: Peers, labels: map[string]string{"protocol": "none"}, value: 0},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			series := c.Series("smesher-0", tc.name, tc.labels)
+			require.Len(t, series, 1)
+			require.Equal(t, tc.value, series[0].Value)
+		})
+	}
+	require.Empty(t, c.Series("smesher-0", "missing", nil))
+	require.Empty(t, c.Series("smesher-1", Goroutines, nil))
+}
+
+func TestScrapeErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/malformed" {
+			_, _ = w.Write([]byte("go_goroutines{ 1\n"))
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := New(time.Second)
+	require.Error(t, c.scrape(context.Background(), "smesher-0", srv.URL))
+	require.Error(t, c.scrape(context.Background(), "smesher-0", srv.URL+"/malformed"))
+	require.Empty(t, c.Nodes())
+}
+
+func TestSnapshotsLimit(t *testing.T) {
+	c := New(time.Second, WithLimit(3))
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		c.add("smesher-0", sampleSnapshot(start.Add(time.Duration(i)*time.Second), Goroutines, float64(i)))
+	}
+	series := c.Series("smesher-0", Goroutines, nil)
+	require.Len(t, series, 3)
+	require.Equal(t, []float64{0, 8, 9}, []float64{series[0].Value, series[1].Value, series[2].Value})
+}
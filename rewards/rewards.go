@@ -0,0 +1,214 @@
+// Package rewards verifies that smeshers are rewarded proportionally to their eligibilities.
+package rewards
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	spacemeshv1 "github.com/spacemeshos/api/release/go/spacemesh/v1"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/spacemeshos/go-spacemesh/systest/cluster"
+	"github.com/spacemeshos/go-spacemesh/systest/stream"
+)
+
+const addressLength = 20
+
+// Coinbase returns unique coinbase address of the node.
+func Coinbase(node *cluster.NodeClient) []byte {
+	hash := sha256.Sum256([]byte("coinbase/" + node.Name))
+	return hash[:addressLength]
+}
+
+// SetCoinbase sets unique coinbase (see Coinbase) on every node.
+// Coinbase is used only in proposals that are created after it was set.
+func SetCoinbase(ctx context.Context, nodes ...*cluster.NodeClient) error {
+	for _, node := range nodes {
+		resp, err := spacemeshv1.NewSmesherServiceClient(node).SetCoinbase(ctx, &spacemeshv1.SetCoinbaseRequest{
+			Id: &spacemeshv1.AccountId{Address: Coinbase(node)},
+		})
+		if err != nil {
+			return fmt.Errorf("set coinbase on %s: %w", node.Name, err)
+		}
+		if code := resp.GetStatus().GetCode(); code != 0 {
+			return fmt.Errorf("set coinbase on %s: code %d: %s", node.Name, code, resp.GetStatus().GetMessage())
+		}
+	}
+	return nil
+}
+
+// created is a proposal created by the node.
+type created struct {
+	layer         uint32
+	node          string
+	eligibilities int
+}
+
+// Collector collects rewards for the coinbase of every node and eligibilities
+// from the proposals created by every node. Only proposals that were included
+// into the layer are rewarded, therefore eligibilities are counted only for them.
+type Collector struct {
+	nodes []*cluster.NodeClient
+
+	mu sync.Mutex
+	// rewards by layer and node name
+	rewards map[uint32]map[string]uint64
+	// created proposals and ids of the proposals reported as included by any node
+	created  map[string]created
+	included map[string]struct{}
+}
+
+// NewCollector creates collector for nodes. Coinbase of the nodes must be set with SetCoinbase.
+func NewCollector(nodes ...*cluster.NodeClient) *Collector {
+	return &Collector{
+		nodes:    nodes,
+		rewards:  map[uint32]map[string]uint64{},
+		created:  map[string]created{},
+		included: map[string]struct{}{},
+	}
+}
+
+func (c *Collector) addReward(layer uint32, node string, amount uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exist := c.rewards[layer]; !exist {
+		c.rewards[layer] = map[string]uint64{}
+	}
+	c.rewards[layer][node] += amount
+}
+
+func (c *Collector) addProposal(node string, proposal *spacemeshv1.Proposal) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := string(proposal.Id)
+	switch proposal.Status {
+	case spacemeshv1.Proposal_Created:
+		c.created[id] = created{
+			layer:         proposal.Layer.Number,
+			node:          node,
+			eligibilities: len(proposal.Eligibilities),
+		}
+	case spacemeshv1.Proposal_Included:
+		c.included[id] = struct{}{}
+	}
+}
+
+// eligibilities of the included proposals by layer and node name.
+func (c *Collector) eligibilities() map[uint32]map[string]int {
+	rst := map[uint32]map[string]int{}
+	for id, proposal := range c.created {
+		if _, exist := c.included[id]; !exist {
+			continue
+		}
+		if _, exist := rst[proposal.layer]; !exist {
+			rst[proposal.layer] = map[string]int{}
+		}
+		rst[proposal.layer][proposal.node] += proposal.eligibilities
+	}
+	return rst
+}
+
+// Run collects rewards and eligibilities until ctx is done.
+func (c *Collector) Run(ctx context.Context) error {
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, node := range c.nodes {
+		node := node
+		eg.Go(func() error {
+			filter := &spacemeshv1.AccountDataFilter{
+				AccountId:        &spacemeshv1.AccountId{Address: Coinbase(node)},
+				AccountDataFlags: uint32(spacemeshv1.AccountDataFlag_ACCOUNT_DATA_FLAG_REWARD),
+			}
+			return stream.Accounts(ctx, node, filter, func(data *spacemeshv1.AccountData) (bool, error) {
+				if reward := data.GetReward(); reward != nil {
+					c.addReward(reward.Layer.Number, node.Name, reward.LayerReward.Value)
+				}
+				return true, nil
+			})
+		})
+		eg.Go(func() error {
+			return stream.Proposals(ctx, node, func(proposal *spacemeshv1.Proposal) (bool, error) {
+				c.addProposal(node.Name, proposal)
+				return true, nil
+			})
+		})
+	}
+	err := eg.Wait()
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+	return err
+}
+
+// Verify that in every layer within [from, to] proposals were included and nodes were rewarded,
+// the share of every node in the layer rewards is equal to its share in the eligibilities
+// of the included proposals, nodes without included proposals were not rewarded,
+// and every node was rewarded at least once.
+func (c *Collector) Verify(from, to uint32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	included := c.eligibilities()
+	var (
+		failures []string
+		rewarded = map[string]struct{}{}
+	)
+	for layer := from; layer <= to; layer++ {
+		eligibilities := included[layer]
+		rewards := c.rewards[layer]
+		if len(eligibilities) == 0 {
+			failures = append(failures, fmt.Sprintf("layer %d: no included proposals", layer))
+		}
+		if len(rewards) == 0 {
+			failures = append(failures, fmt.Sprintf("layer %d: no rewards", layer))
+		}
+		if len(eligibilities) == 0 || len(rewards) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(eligibilities))
+		totalEligibilities := new(big.Int)
+		for name, count := range eligibilities {
+			names = append(names, name)
+			totalEligibilities.Add(totalEligibilities, big.NewInt(int64(count)))
+		}
+		sort.Strings(names)
+		totalRewards := new(big.Int)
+		for name, reward := range rewards {
+			rewarded[name] = struct{}{}
+			totalRewards.Add(totalRewards, new(big.Int).SetUint64(reward))
+			if _, exist := eligibilities[name]; !exist {
+				failures = append(failures, fmt.Sprintf("layer %d: %s rewarded without included proposals", layer, name))
+			}
+		}
+		for _, name := range names {
+			count := eligibilities[name]
+			reward, exist := rewards[name]
+			if !exist {
+				failures = append(failures, fmt.Sprintf("layer %d: %s has %d eligibilities, but no rewards",
+					layer, name, count))
+				continue
+			}
+			// reward / total rewards == eligibilities / total eligibilities
+			share := new(big.Int).Mul(new(big.Int).SetUint64(reward), totalEligibilities)
+			expected := new(big.Int).Mul(totalRewards, big.NewInt(int64(count)))
+			if share.Cmp(expected) != 0 {
+				failures = append(failures, fmt.Sprintf(
+					"layer %d: %s has %d/%s eligibilities, but rewarded %d/%s",
+					layer, name, count, totalEligibilities, reward, totalRewards))
+			}
+		}
+	}
+	for _, node := range c.nodes {
+		if _, exist := rewarded[node.Name]; !exist {
+			failures = append(failures, fmt.Sprintf("%s wasn't rewarded in layers [%d, %d]", node.Name, from, to))
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "\n"))
+	}
+	return nil
+}
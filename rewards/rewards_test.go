@@ -0,0 +1,132 @@
+package rewards
+
+import (
+	"testing"
+
+	spacemeshv1 "github.com/spacemeshos/api/release/go/spacemesh/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/systest/cluster"
+)
+
+type testProposal struct {
+	id            string
+	node          string
+	layer         uint32
+	eligibilities int
+	included      bool
+}
+
+func TestVerify(t *testing.T) {
+	nodes := []*cluster.NodeClient{
+		{Node: cluster.Node{Name: "a"}},
+		{Node: cluster.Node{Name: "b"}},
+	}
+	for _, tc := range []struct {
+		desc      string
+		proposals []testProposal
+		rewards   map[uint32]map[string]uint64
+		err       bool
+	}{
+		{
+			desc: "proportional",
+			proposals: []testProposal{
+				{id: "a1", node: "a", layer: 1, eligibilities: 1, included: true},
+				{id: "b1", node: "b", layer: 1, eligibilities: 3, included: true},
+				{id: "a2", node: "a", layer: 2, eligibilities: 2, included: true},
+				{id: "b2", node: "b", layer: 2, eligibilities: 1, included: true},
+			},
+			rewards: map[uint32]map[string]uint64{1: {"a": 10, "b": 30}, 2: {"a": 20, "b": 10}},
+		},
+		{
+			desc: "not proportional",
+			proposals: []testProposal{
+				{id: "a1", node: "a", layer: 1, eligibilities: 1, included: true},
+				{id: "b1", node: "b", layer: 1, eligibilities: 3, included: true},
+				{id: "a2", node: "a", layer: 2, eligibilities: 2, included: true},
+				{id: "b2", node: "b", layer: 2, eligibilities: 1, included: true},
+			},
+			rewards: map[uint32]map[string]uint64{1: {"a": 10, "b": 31}, 2: {"a": 20, "b": 10}},
+			err:     true,
+		},
+		{
+			desc: "not included proposal",
+			proposals: []testProposal{
+				{id: "a1", node: "a", layer: 1, eligibilities: 1, included: true},
+				{id: "b1", node: "b", layer: 1, eligibilities: 3},
+				{id: "a2", node: "a", layer: 2, eligibilities: 2, included: true},
+				{id: "b2", node: "b", layer: 2, eligibilities: 1, included: true},
+			},
+			rewards: map[uint32]map[string]uint64{1: {"a": 10}, 2: {"a": 20, "b": 10}},
+		},
+		{
+			desc: "rewarded for not included proposal",
+			proposals: []testProposal{
+				{id: "a1", node: "a", layer: 1, eligibilities: 1, included: true},
+				{id: "b1", node: "b", layer: 1, eligibilities: 1},
+				{id: "a2", node: "a", layer: 2, eligibilities: 1, included: true},
+				{id: "b2", node: "b", layer: 2, eligibilities: 1, included: true},
+			},
+			rewards: map[uint32]map[string]uint64{1: {"a": 10, "b": 10}, 2: {"a": 10, "b": 10}},
+			err:     true,
+		},
+		{
+			desc: "empty layer",
+			proposals: []testProposal{
+				{id: "a1", node: "a", layer: 1, eligibilities: 1, included: true},
+				{id: "b1", node: "b", layer: 1, eligibilities: 1, included: true},
+			},
+			rewards: map[uint32]map[string]uint64{1: {"a": 10, "b": 10}},
+			err:     true,
+		},
+		{
+			desc: "no rewards in layer",
+			proposals: []testProposal{
+				{id: "a1", node: "a", layer: 1, eligibilities: 1, included: true},
+				{id: "b1", node: "b", layer: 1, eligibilities: 1, included: true},
+				{id: "a2", node: "a", layer: 2, eligibilities: 1, included: true},
+				{id: "b2", node: "b", layer: 2, eligibilities: 1, included: true},
+			},
+			rewards: map[uint32]map[string]uint64{1: {"a": 10, "b": 10}},
+			err:     true,
+		},
+		{
+			desc: "node never rewarded",
+			proposals: []testProposal{
+				{id: "a1", node: "a", layer: 1, eligibilities: 1, included: true},
+				{id: "a2", node: "a", layer: 2, eligibilities: 1, included: true},
+			},
+			rewards: map[uint32]map[string]uint64{1: {"a": 10}, 2: {"a": 10}},
+			err:     true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			collector := NewCollector(nodes...)
+			for _, proposal := range tc.proposals {
+				created := &spacemeshv1.Proposal{
+					Id:            []byte(proposal.id),
+					Layer:         &spacemeshv1.LayerNumber{Number: proposal.layer},
+					Eligibilities: make([]*spacemeshv1.Eligibility, proposal.eligibilities),
+					Status:        spacemeshv1.Proposal_Created,
+				}
+				collector.addProposal(proposal.node, created)
+				if proposal.included {
+					// inclusion may be reported by any node
+					collector.addProposal("b", &spacemeshv1.Proposal{
+						Id:     created.Id,
+						Layer:  created.Layer,
+						Status: spacemeshv1.Proposal_Included,
+					})
+				}
+			}
+			collector.rewards = tc.rewards
+			err := collector.Verify(1, 2)
+			if tc.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package stream
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	spacemeshv1 "github.com/spacemeshos/api/release/go/spacemesh/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/spacemeshos/go-spacemesh/systest/cluster"
+)
+
+// LayersOpener opens MeshService.LayerStream.
+func LayersOpener(ctx context.Context, node *cluster.NodeClient) (Recv, error) {
+	layers, err := spacemeshv1.NewMeshServiceClient(node).LayerStream(ctx, &spacemeshv1.LayerStreamRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return func() (proto.Message, error) { return layers.Recv() }, nil
+}
+
+// Layers watches layers of the node. Layer is delivered once for every status.
+func Layers(ctx context.Context, node *cluster.NodeClient,
+	handler func(*spacemeshv1.LayerStreamResponse) (bool, error), opts ...Opt) error {
+	return Watch(ctx, node, LayersOpener, func(msg proto.Message) (bool, error) {
+		return handler(msg.(*spacemeshv1.LayerStreamResponse))
+	}, opts...)
+}
+
+// ProposalsOpener opens DebugService.ProposalsStream.
+func ProposalsOpener(ctx context.Context, node *cluster.NodeClient) (Recv, error) {
+	proposals, err := spacemeshv1.NewDebugServiceClient(node).ProposalsStream(ctx, &empty.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	return func() (proto.Message, error) { return proposals.Recv() }, nil
+}
+
+// Proposals watches proposal events of the node.
+func Proposals(ctx context.Context, node *cluster.NodeClient,
+	handler func(*spacemeshv1.Proposal) (bool, error), opts ...Opt) error {
+	return Watch(ctx, node, ProposalsOpener, func(msg proto.Message) (bool, error) {
+		return handler(msg.(*spacemeshv1.Proposal))
+	}, opts...)
+}
+
+// AccountsOpener returns opener for GlobalStateService.AccountDataStream with filter.
+func AccountsOpener(filter *spacemeshv1.AccountDataFilter) Opener {
+	return func(ctx context.Context, node *cluster.NodeClient) (Recv, error) {
+		accounts, err := spacemeshv1.NewGlobalStateServiceClient(node).AccountDataStream(ctx,
+			&spacemeshv1.AccountDataStreamRequest{Filter: filter})
+		if err != nil {
+			return nil, err
+		}
+		return func() (proto.Message, error) { return accounts.Recv() }, nil
+	}
+}
+
+// Accounts watches account data (rewards, receipts or account state) that match filter.
+func Accounts(ctx context.Context, node *cluster.NodeClient, filter *spacemeshv1.AccountDataFilter,
+	handler func(*spacemeshv1.AccountData) (bool, error), opts ...Opt) error {
+	return Watch(ctx, node, AccountsOpener(filter), func(msg proto.Message) (bool, error) {
+		return handler(msg.(*spacemeshv1.AccountDataStreamResponse).Datum)
+	}, opts...)
+}
+
+// GlobalStateOpener returns opener for GlobalStateService.GlobalStateStream for data
+// selected by flags (bit field of spacemeshv1.GlobalStateDataFlag).
+func GlobalStateOpener(flags uint32) Opener {
+	return func(ctx context.Context, node *cluster.NodeClient) (Recv, error) {
+		states, err := spacemeshv1.NewGlobalStateServiceClient(node).GlobalStateStream(ctx,
+			&spacemeshv1.GlobalStateStreamRequest{GlobalStateDataFlags: flags})
+		if err != nil {
+			return nil, err
+		}
+		return func() (proto.Message, error) { return states.Recv() }, nil
+	}
+}
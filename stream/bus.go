@@ -0,0 +1,229 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	spacemeshv1 "github.com/spacemeshos/api/release/go/spacemesh/v1"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/spacemeshos/go-spacemesh/systest/cluster"
+)
+
+const subscriptionBuffer = 1024
+
+// Event is a message received from the node.
+type Event struct {
+	Node     string
+	Received time.Time
+	Message  proto.Message
+	// Err is set if stream from the node failed and will not be reopened. Message is nil in such case.
+	// Errors are delivered to every subscription regardless of filters.
+	Err error
+}
+
+// Layer returns layer if event is from LayerStream, otherwise nil.
+func (ev *Event) Layer() *spacemeshv1.LayerStreamResponse {
+	layer, _ := ev.Message.(*spacemeshv1.LayerStreamResponse)
+	return layer
+}
+
+// Proposal returns proposal if event is from ProposalsStream, otherwise nil.
+func (ev *Event) Proposal() *spacemeshv1.Proposal {
+	proposal, _ := ev.Message.(*spacemeshv1.Proposal)
+	return proposal
+}
+
+// GlobalState returns data (receipt, reward, account or state hash) if event is
+// from GlobalStateStream, otherwise nil.
+func (ev *Event) GlobalState() *spacemeshv1.GlobalStateData {
+	state, ok := ev.Message.(*spacemeshv1.GlobalStateStreamResponse)
+	if !ok {
+		return nil
+	}
+	return state.Datum
+}
+
+// Filter selects events for the subscription.
+type Filter func(*Event) bool
+
+// LayerEvents selects events from LayerStream.
+func LayerEvents() Filter {
+	return func(ev *Event) bool {
+		return ev.Layer() != nil
+	}
+}
+
+// ProposalEvents selects events from ProposalsStream.
+func ProposalEvents() Filter {
+	return func(ev *Event) bool {
+		return ev.Proposal() != nil
+	}
+}
+
+// GlobalStateEvents selects events from GlobalStateStream.
+func GlobalStateEvents() Filter {
+	return func(ev *Event) bool {
+		return ev.GlobalState() != nil
+	}
+}
+
+// FromNode selects events received from the node.
+func FromNode(name string) Filter {
+	return func(ev *Event) bool {
+		return ev.Node == name
+	}
+}
+
+// All selects events that are selected by every filter.
+func All(filters ...Filter) Filter {
+	return func(ev *Event) bool {
+		for _, filter := range filters {
+			if !filter(ev) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// DefaultOpeners for the bus: layers, proposals, and transaction receipts, rewards and accounts.
+func DefaultOpeners() []Opener {
+	return []Opener{
+		LayersOpener,
+		ProposalsOpener,
+		GlobalStateOpener(uint32(spacemeshv1.GlobalStateDataFlag_GLOBAL_STATE_DATA_FLAG_TRANSACTION_RECEIPT |
+			spacemeshv1.GlobalStateDataFlag_GLOBAL_STATE_DATA_FLAG_REWARD |
+			spacemeshv1.GlobalStateDataFlag_GLOBAL_STATE_DATA_FLAG_ACCOUNT)),
+	}
+}
+
+// Bus opens every stream once per node, and delivers events from all nodes
+// to any number of subscriptions.
+//
+//	bus := stream.NewBus()
+//	sub := bus.Subscribe(stream.ProposalEvents())
+//	eg.Go(func() error { return bus.Run(ctx, nodes, stream.DefaultOpeners()...) })
+//	eg.Go(func() error { return sub.Watch(ctx, handler) })
+//
+// Subscriptions receive only events that were published after Subscribe.
+type Bus struct {
+	opts []Opt
+
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+// NewBus creates bus. Options are used for every stream.
+func NewBus(opts ...Opt) *Bus {
+	return &Bus{opts: opts, subs: map[*Subscription]struct{}{}}
+}
+
+// Run watches streams on every node and publishes received events, until ctx is canceled.
+// If stream from the node fails, the error is published to subscriptions and streams from other
+// nodes are not interrupted. Run returns the first such error once all streams are stopped.
+func (b *Bus) Run(ctx context.Context, nodes []*cluster.NodeClient, openers ...Opener) error {
+	var eg errgroup.Group
+	for _, node := range nodes {
+		node := node
+		for _, open := range openers {
+			open := open
+			eg.Go(func() error {
+				err := Watch(ctx, node, open, func(msg proto.Message) (bool, error) {
+					b.publish(ctx, &Event{Node: node.Name, Received: time.Now(), Message: msg})
+					return true, nil
+				}, b.opts...)
+				if err != nil && ctx.Err() == nil {
+					b.publish(ctx, &Event{Node: node.Name, Received: time.Now(), Err: err})
+				}
+				return err
+			})
+		}
+	}
+	err := eg.Wait()
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+func (b *Bus) publish(ctx context.Context, ev *Event) {
+	b.mu.Lock()
+	subs := make([]*Subscription, 0, len(b.subs))
+	for sub := range b.subs {
+		subs = append(subs, sub)
+	}
+	b.mu.Unlock()
+	for _, sub := range subs {
+		if ev.Err == nil && !sub.filter(ev) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.done:
+		case sub.events <- ev:
+		}
+	}
+}
+
+// Subscribe registers subscription for events selected by filters.
+// Subscription must be closed once it is not used, as it blocks the bus when buffer is full.
+func (b *Bus) Subscribe(filters ...Filter) *Subscription {
+	sub := &Subscription{
+		bus:    b,
+		filter: All(filters...),
+		events: make(chan *Event, subscriptionBuffer),
+		done:   make(chan struct{}),
+	}
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub
+}
+
+// Subscription receives events from the bus.
+type Subscription struct {
+	bus    *Bus
+	filter Filter
+	events chan *Event
+	once   sync.Once
+	done   chan struct{}
+}
+
+// Events returns channel with events.
+func (s *Subscription) Events() <-chan *Event {
+	return s.events
+}
+
+// Close unregisters subscription.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s)
+		s.bus.mu.Unlock()
+		close(s.done)
+	})
+}
+
+// Watch executes handler for every event until handler returns false or an error,
+// ctx is canceled, or stream from one of the nodes failed. Subscription is closed when Watch returns.
+func (s *Subscription) Watch(ctx context.Context, handler func(*Event) (bool, error)) error {
+	defer s.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev := <-s.events:
+			if ev.Err != nil {
+				return ev.Err
+			}
+			if cont, err := handler(ev); !cont {
+				return err
+			}
+		}
+	}
+}
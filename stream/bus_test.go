@@ -0,0 +1,20 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionFailsOnStreamError(t *testing.T) {
+	bus := NewBus()
+	sub := bus.Subscribe(ProposalEvents())
+	failure := errors.New("stream failed")
+	bus.publish(context.Background(), &Event{Node: "smesher-1", Err: failure})
+	err := sub.Watch(context.Background(), func(*Event) (bool, error) {
+		return true, nil
+	})
+	require.ErrorIs(t, err, failure)
+}
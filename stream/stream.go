@@ -0,0 +1,222 @@
+// Package stream watches grpc server streams of the smesher api.
+// Streams are reopened when they fail, for example after the node was restarted by chaos,
+// and recent events that were already delivered are not delivered again.
+package stream
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/spacemeshos/go-spacemesh/systest/cluster"
+)
+
+// Recv reads next message from the stream.
+type Recv func() (proto.Message, error)
+
+// Opener opens the stream on the node. Stream must be closed when ctx is canceled.
+type Opener func(ctx context.Context, node *cluster.NodeClient) (Recv, error)
+
+// Key identifies the message. Messages with the key that was already seen are dropped.
+type Key func(proto.Message) string
+
+// Handler is executed for every unique message until it returns false or an error.
+type Handler func(proto.Message) (bool, error)
+
+// Opt is for configuring watcher.
+type Opt func(*options)
+
+type options struct {
+	key        Key
+	window     int
+	retries    int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	log        *zap.SugaredLogger
+}
+
+// WithKey overwrites key used for deduplication. By default message is identified by its encoding,
+// so only exactly equal messages are dropped.
+func WithKey(key Key) Opt {
+	return func(o *options) {
+		o.key = key
+	}
+}
+
+// WithWindow overwrites the number of the most recent keys that are remembered for deduplication.
+// Streams are expected to replay only recent messages when reopened. Zero disables deduplication.
+func WithWindow(n int) Opt {
+	return func(o *options) {
+		o.window = n
+	}
+}
+
+// WithRetries overwrites the number of consecutive attempts to reopen the stream, after which watch fails.
+// Attempts are reset once stream delivers a message.
+func WithRetries(n int) Opt {
+	return func(o *options) {
+		o.retries = n
+	}
+}
+
+// WithBackoff overwrites minimal and maximal delay between attempts to reopen the stream.
+func WithBackoff(min, max time.Duration) Opt {
+	return func(o *options) {
+		o.minBackoff = min
+		o.maxBackoff = max
+	}
+}
+
+// WithLogger logs every time when stream is reopened.
+func WithLogger(log *zap.SugaredLogger) Opt {
+	return func(o *options) {
+		o.log = log
+	}
+}
+
+func encoded(msg proto.Message) string {
+	buf, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return ""
+	}
+	return string(buf)
+}
+
+// retryable returns false for errors that will not be fixed by reopening the stream.
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unimplemented, codes.InvalidArgument, codes.PermissionDenied, codes.Unauthenticated:
+		return false
+	}
+	return true
+}
+
+// dedup remembers a bounded number of the most recent keys.
+type dedup struct {
+	keys  map[string]struct{}
+	order []string
+	next  int
+}
+
+func newDedup(window int) *dedup {
+	return &dedup{keys: make(map[string]struct{}, window), order: make([]string, 0, window)}
+}
+
+// add returns false if key is already remembered. Otherwise key is remembered
+// and the oldest key is forgotten if window is full.
+func (d *dedup) add(key string) bool {
+	if cap(d.order) == 0 {
+		return true
+	}
+	if _, exist := d.keys[key]; exist {
+		return false
+	}
+	d.keys[key] = struct{}{}
+	if len(d.order) < cap(d.order) {
+		d.order = append(d.order, key)
+		return true
+	}
+	delete(d.keys, d.order[d.next])
+	d.order[d.next] = key
+	d.next = (d.next + 1) % len(d.order)
+	return true
+}
+
+// Watch opens the stream on the node and executes handler for every unique message,
+// until handler returns false or an error, or ctx is canceled.
+//
+// Stream is reopened if it fails with a retryable error. Before stream is reopened node is redialed,
+// as ip of the pod changes when it is restarted. Watch fails if stream failed after the configured
+// number of consecutive attempts.
+func Watch(ctx context.Context, node *cluster.NodeClient, open Opener, handler Handler, opts ...Opt) error {
+	o := options{
+		key:        encoded,
+		window:     10000,
+		retries:    10,
+		minBackoff: time.Second,
+		maxBackoff: 30 * time.Second,
+		log:        zap.NewNop().Sugar(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var (
+		seen     = newDedup(o.window)
+		backoff  = o.minBackoff
+		attempts = 0
+		current  = node
+	)
+	defer func() {
+		if current != node {
+			current.Close()
+		}
+	}()
+	for {
+		done, received, err := watchOnce(ctx, current, open, handler, seen, o.key)
+		if done {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !retryable(err) {
+			return fmt.Errorf("stream from %s: %w", node.Name, err)
+		}
+		if received {
+			backoff = o.minBackoff
+			attempts = 0
+		}
+		if attempts++; attempts > o.retries {
+			return fmt.Errorf("stream from %s failed after %d attempts: %w", node.Name, o.retries, err)
+		}
+		o.log.Debugw("reopening stream", "node", node.Name, "backoff", backoff, "attempt", attempts, "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > o.maxBackoff {
+			backoff = o.maxBackoff
+		}
+		redialed, err := node.Redial(ctx)
+		if err != nil {
+			// stream will be reopened on the current connection
+			o.log.Debugw("failed to redial node", "node", node.Name, "error", err)
+			continue
+		}
+		if current != node {
+			current.Close()
+		}
+		current = redialed
+	}
+}
+
+// watchOnce reads stream until it fails. done is true if watch must not continue,
+// received is true if stream delivered at least one message.
+func watchOnce(ctx context.Context, node *cluster.NodeClient, open Opener, handler Handler,
+	seen *dedup, key Key) (done, received bool, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	recv, err := open(ctx, node)
+	if err != nil {
+		return false, false, err
+	}
+	for {
+		msg, err := recv()
+		if err != nil {
+			return false, received, err
+		}
+		received = true
+		if id := key(msg); len(id) > 0 && !seen.add(id) {
+			continue
+		}
+		if cont, err := handler(msg); !cont {
+			return true, received, err
+		}
+	}
+}
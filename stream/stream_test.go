@@ -0,0 +1,25 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedup(t *testing.T) {
+	seen := newDedup(2)
+	require.True(t, seen.add("a"))
+	require.False(t, seen.add("a"))
+	require.True(t, seen.add("b"))
+	require.True(t, seen.add("c"))
+	require.Len(t, seen.keys, 2)
+	require.True(t, seen.add("a"), "oldest key must be forgotten")
+	require.False(t, seen.add("c"))
+	require.True(t, seen.add("b"))
+}
+
+func TestDedupDisabled(t *testing.T) {
+	seen := newDedup(0)
+	require.True(t, seen.add("a"))
+	require.True(t, seen.add("a"))
+}
@@ -0,0 +1,150 @@
+package testcontext
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apimetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// collectTimeout bounds time spent on collecting artifacts, so that cleanup
+// will run even if k8s api is unresponsive.
+const collectTimeout = 5 * time.Minute
+
+func artifactsName(name string) string {
+	return strings.NewReplacer("/", "_", " ", "_").Replace(name)
+}
+
+// collectArtifacts writes logs and statuses of every pod in the namespace, and events
+// from the namespace into the dir.
+func collectArtifacts(cctx *Context, dir string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create artifacts dir %s: %w", dir, err)
+	}
+	pods, err := cctx.Client.CoreV1().Pods(cctx.Namespace).List(ctx, apimetav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list pods in %s: %w", cctx.Namespace, err)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if err := collectPod(ctx, cctx, filepath.Join(dir, pod.Name), pod); err != nil {
+			cctx.Log.Warnw("failed to collect pod artifacts", "pod", pod.Name, "error", err)
+		}
+	}
+	if err := collectEvents(ctx, cctx, filepath.Join(dir, "events.txt")); err != nil {
+		cctx.Log.Warnw("failed to collect events", "error", err)
+	}
+	return nil
+}
+
+// collectPod writes pod status and logs of every container into the dir. Failure to collect logs
+// of one container doesn't prevent collecting logs from other containers, errors are combined.
+func collectPod(ctx context.Context, cctx *Context, dir string, pod *v1.Pod) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	var failures []string
+	if err := writeJSON(filepath.Join(dir, "pod.json"), pod); err != nil {
+		failures = append(failures, err.Error())
+	}
+	restarts := map[string]int32{}
+	for _, status := range pod.Status.ContainerStatuses {
+		restarts[status.Name] = status.RestartCount
+	}
+	for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		err := collectLogs(ctx, cctx, filepath.Join(dir, container.Name+".log"), pod.Name, container.Name, false)
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+		if restarts[container.Name] == 0 {
+			continue
+		}
+		err = collectLogs(ctx, cctx, filepath.Join(dir, container.Name+".previous.log"), pod.Name, container.Name, true)
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func collectLogs(ctx context.Context, cctx *Context, path, pod, container string, previous bool) error {
+	stream, err := cctx.Client.CoreV1().Pods(cctx.Namespace).GetLogs(pod, &v1.PodLogOptions{
+		Container: container,
+		Previous:  previous,
+	}).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("stream logs for %s/%s: %w", pod, container, err)
+	}
+	defer stream.Close()
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, stream); err != nil {
+		return fmt.Errorf("copy logs for %s/%s: %w", pod, container, err)
+	}
+	return nil
+}
+
+func collectEvents(ctx context.Context, cctx *Context, path string) error {
+	events, err := cctx.Client.CoreV1().Events(cctx.Namespace).List(ctx, apimetav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list events in %s: %w", cctx.Namespace, err)
+	}
+	sort.Slice(events.Items, func(i, j int) bool {
+		return eventTime(&events.Items[i]).Before(eventTime(&events.Items[j]))
+	})
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := tabwriter.NewWriter(f, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tTYPE\tREASON\tOBJECT\tCOUNT\tMESSAGE")
+	for i := range events.Items {
+		ev := &events.Items[i]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s/%s\t%d\t%s\n",
+			eventTime(ev).Format(time.RFC3339),
+			ev.Type,
+			ev.Reason,
+			strings.ToLower(ev.InvolvedObject.Kind), ev.InvolvedObject.Name,
+			ev.Count,
+			strings.TrimSpace(ev.Message),
+		)
+	}
+	return w.Flush()
+}
+
+func eventTime(ev *v1.Event) time.Time {
+	if !ev.LastTimestamp.IsZero() {
+		return ev.LastTimestamp.Time
+	}
+	if !ev.EventTime.IsZero() {
+		return ev.EventTime.Time
+	}
+	return ev.CreationTimestamp.Time
+}
+
+func writeJSON(path string, obj interface{}) error {
+	data, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,62 @@
+package testcontext
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const chaosCleanupTimeout = time.Minute
+
+// chaosRegistry tracks chaos objects that were created by the test,
+// so that they are removed even if the test didn't call teardown.
+type chaosRegistry struct {
+	mu      sync.Mutex
+	objects map[string]client.Object
+}
+
+func chaosKey(obj client.Object) string {
+	return fmt.Sprintf("%T/%s", obj, obj.GetName())
+}
+
+// RegisterChaos tracks chaos object that must be deleted on cleanup.
+func (c *Context) RegisterChaos(obj client.Object) {
+	c.chaos.mu.Lock()
+	defer c.chaos.mu.Unlock()
+	if c.chaos.objects == nil {
+		c.chaos.objects = map[string]client.Object{}
+	}
+	c.chaos.objects[chaosKey(obj)] = obj
+}
+
+// UnregisterChaos stops tracking chaos object, after it was deleted by teardown.
+func (c *Context) UnregisterChaos(obj client.Object) {
+	c.chaos.mu.Lock()
+	defer c.chaos.mu.Unlock()
+	delete(c.chaos.objects, chaosKey(obj))
+}
+
+// clearChaos deletes all registered chaos objects.
+func (c *Context) clearChaos() {
+	c.chaos.mu.Lock()
+	objects := c.chaos.objects
+	c.chaos.objects = nil
+	c.chaos.mu.Unlock()
+	if len(objects) == 0 {
+		return
+	}
+	// test context may be already canceled
+	ctx, cancel := context.WithTimeout(context.Background(), chaosCleanupTimeout)
+	defer cancel()
+	for _, obj := range objects {
+		if err := c.Generic.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			c.Log.Errorw("failed to delete chaos", "name", obj.GetName(), "error", err)
+			continue
+		}
+		c.Report.Event("chaos", obj.GetName(), "removed on cleanup")
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"math/rand"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
@@ -35,20 +37,29 @@ var (
 	clusterSize  = flag.Int("size", 10, "size of the cluster. all test must use at most this number of smeshers")
 	testTimeout  = flag.Duration("test-timeout", 30*time.Minute, "timeout for a single test")
 	keep         = flag.Bool("keep", false, "if true cluster will not be removed after test is finished")
-	clusters     = flag.Int("clusters", 1, "controls how many clusters are deployed on k8s")
+	clusters     = flag.Int("clusters", 1, "tests are admitted in parallel while they request at most -clusters * -size pods")
 	nodeSelector = stringToString{}
-	labels       = stringSet{}
-	tokens       chan struct{}
+	labels       = &labelExpr{}
+	list         = flag.Bool("list", false, "if true tests that match labels are printed and skipped")
+
+	artifacts = flag.String("artifacts", "artifacts",
+		"directory for logs, statuses and events collected from the cluster of the failed test")
+	collectAlways = flag.Bool("collect-always", false, "if true artifacts will be collected even if test succeeded")
+
+	namespaceTTL = flag.Duration("ttl", 0,
+		"namespace of the test is deleted by the sweeper after ttl, even if cleanup didn't run. if zero twice the -test-timeout is used")
+	sweepFlag = flag.Bool("sweep", true, "if true namespaces with expired ttl are deleted before the first test")
 )
 
 func init() {
-	tokens = make(chan struct{}, *clusterSize)
 	flag.Var(nodeSelector, "node-selector", "select where test pods will be scheduled")
-	flag.Var(labels, "labels", "test will be executed only if it matches all labels")
+	flag.Var(labels, "labels",
+		"boolean expression over test labels, such as 'sanity && !slow' or 'longevity || chaos'. "+
+			"test will be executed only if expression is true for its labels")
 }
 
-func rngName() string {
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+func rngName(seed int64) string {
+	rng := rand.New(rand.NewSource(seed))
 	const choices = "qwertyuiopasdfghjklzxcvbnm"
 	buf := make([]byte, 4)
 	for i := range buf {
@@ -61,6 +72,7 @@ func rngName() string {
 type Context struct {
 	context.Context
 	Client            *kubernetes.Clientset
+	Config            *rest.Config
 	BootstrapDuration time.Duration
 	ClusterSize       int
 	Generic           client.Client
@@ -69,6 +81,9 @@ type Context struct {
 	PoetImage         string
 	NodeSelector      map[string]string
 	Log               *zap.SugaredLogger
+	Report            *Report
+
+	chaos *chaosRegistry
 }
 
 func cleanup(tb testing.TB, f func()) {
@@ -90,8 +105,11 @@ func deleteNamespace(ctx *Context) error {
 	return nil
 }
 
-func deployNamespace(ctx *Context) error {
-	_, err := ctx.Client.CoreV1().Namespaces().Apply(ctx, corev1.Namespace(ctx.Namespace),
+// deployNamespace creates namespace labeled with owner, creation time and ttl.
+// Zero ttl means that namespace is never deleted by the sweeper.
+func deployNamespace(ctx *Context, ttl time.Duration) error {
+	ns := corev1.Namespace(ctx.Namespace).WithLabels(namespaceLabels(time.Now(), ttl))
+	_, err := ctx.Client.CoreV1().Namespaces().Apply(ctx, ns,
 		apimetav1.ApplyOptions{FieldManager: "test"})
 	if err != nil {
 		return fmt.Errorf("create namespace %s: %w", ctx.Namespace, err)
@@ -108,17 +126,37 @@ func Labels(labels ...string) Opt {
 	}
 }
 
+// Size overwrites cluster size for the test. By default -size is used.
+// Test will be started once there is a capacity for this number of pods.
+func Size(size int) Opt {
+	return func(c *cfg) {
+		c.size = size
+	}
+}
+
+// Shared marks the test as a read-only user of the long-lived cluster with the name.
+// Such cluster is deployed once in the namespace "shared-<name>", or in -namespace if it is set,
+// and the namespace is not deleted after the test.
+func Shared(name string) Opt {
+	return func(c *cfg) {
+		c.shared = name
+	}
+}
+
 // Opt is for configuring Context.
 type Opt func(*cfg)
 
 func newCfg() *cfg {
 	return &cfg{
 		labels: map[string]struct{}{},
+		size:   *clusterSize,
 	}
 }
 
 type cfg struct {
 	labels map[string]struct{}
+	size   int
+	shared string
 }
 
 // New creates context for the test.
@@ -129,13 +167,20 @@ func New(t *testing.T, opts ...Opt) *Context {
 	for _, opt := range opts {
 		opt(c)
 	}
-	for label := range labels {
-		if _, exist := c.labels[label]; !exist {
-			t.Skipf("not labeled with '%s'", label)
+	if !labels.Match(c.labels) {
+		if *list {
+			t.SkipNow()
 		}
+		report := newReport(t.Name(), c.labels)
+		report.Status = StatusSkipped
+		report.Message = fmt.Sprintf("labels %v don't match '%s'", report.Labels, labels)
+		reports.add(report)
+		t.Skip(report.Message)
+	}
+	if *list {
+		fmt.Printf("%s\t%s\n", t.Name(), strings.Join(newReport(t.Name(), c.labels).Labels, ","))
+		t.SkipNow()
 	}
-	tokens <- struct{}{}
-	t.Cleanup(func() { <-tokens })
 
 	config, err := rest.InClusterConfig()
 	require.NoError(t, err)
@@ -143,9 +188,21 @@ func New(t *testing.T, opts ...Opt) *Context {
 	clientset, err := kubernetes.NewForConfig(config)
 	require.NoError(t, err)
 
+	start := time.Now()
 	ns := *namespaceFlag
-	if len(ns) == 0 {
-		ns = "test-" + rngName()
+	if len(ns) == 0 && len(c.shared) > 0 {
+		ns = "shared-" + c.shared
+	} else if len(ns) == 0 {
+		ns = "test-" + rngName(start.UnixNano())
+	}
+	admit, cancelAdmit := context.WithTimeout(context.Background(), *testTimeout)
+	defer cancelAdmit()
+	if len(c.shared) > 0 {
+		require.NoError(t, pods.acquireShared(admit, ns, c.size))
+	} else {
+		release, err := pods.acquire(admit, c.size)
+		require.NoError(t, err)
+		t.Cleanup(release)
 	}
 	scheme := runtime.NewScheme()
 	require.NoError(t, chaosoperatorv1alpha1.AddToScheme(scheme))
@@ -160,23 +217,55 @@ func New(t *testing.T, opts ...Opt) *Context {
 		Namespace:         ns,
 		BootstrapDuration: *bootstrapDuration,
 		Client:            clientset,
+		Config:            config,
 		Generic:           generic,
-		ClusterSize:       *clusterSize,
+		ClusterSize:       c.size,
 		Image:             *imageFlag,
 		PoetImage:         *poetImage,
 		NodeSelector:      nodeSelector,
 		Log:               zaptest.NewLogger(t, zaptest.Level(logLevel)).Sugar(),
+		Report:            newReport(t.Name(), c.labels),
+		chaos:             &chaosRegistry{},
 	}
-	if !*keep {
-		cleanup(t, func() {
-			if err := deleteNamespace(cctx); err != nil {
-				cctx.Log.Errorf("cleanup failed", "error", err)
-				return
+	cctx.Report.Param("namespace", cctx.Namespace)
+	cctx.Report.Param("image", cctx.Image)
+	cctx.Report.Param("poet-image", cctx.PoetImage)
+	cctx.Report.Param("size", cctx.ClusterSize)
+	cctx.Report.Param("start-time", start.UTC().Format(time.RFC3339Nano))
+	t.Cleanup(func() {
+		cctx.Report.finish(t)
+		reports.add(cctx.Report)
+	})
+	cleanup(t, func() {
+		if t.Failed() || *collectAlways {
+			dir := filepath.Join(*artifacts, artifactsName(t.Name()))
+			if err := collectArtifacts(cctx, dir); err != nil {
+				cctx.Log.Errorw("collecting artifacts failed", "error", err)
+			} else {
+				cctx.Log.Infow("collected artifacts", "dir", dir)
 			}
-			cctx.Log.Debug("cleanup completed")
-		})
+		}
+		cctx.clearChaos()
+		if *keep || len(c.shared) > 0 {
+			return
+		}
+		if err := deleteNamespace(cctx); err != nil {
+			cctx.Log.Errorf("cleanup failed", "error", err)
+			return
+		}
+		cctx.Log.Debug("cleanup completed")
+	})
+	if *sweepFlag {
+		sweepOnStart(cctx)
+	}
+	ttl := *namespaceTTL
+	if ttl == 0 {
+		ttl = 2 * *testTimeout
+	}
+	if *keep || len(c.shared) > 0 {
+		ttl = 0
 	}
-	require.NoError(t, deployNamespace(cctx))
+	require.NoError(t, deployNamespace(cctx, ttl))
 	cctx.Log.Infow("using", "namespace", cctx.Namespace)
 	return cctx
 }
@@ -0,0 +1,225 @@
+package testcontext
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// labelExpr is a boolean expression over test labels, for example:
+//
+//	sanity && !slow
+//	(longevity || chaos) && !flaky
+//
+// Comma is an alias for &&, and every repeated flag is joined with &&.
+type labelExpr struct {
+	raw  []string
+	root node
+}
+
+func (l *labelExpr) Set(val string) error {
+	if len(strings.TrimSpace(val)) == 0 {
+		return nil
+	}
+	parsed, err := parseLabels(val)
+	if err != nil {
+		return err
+	}
+	l.raw = append(l.raw, val)
+	if l.root == nil {
+		l.root = parsed
+	} else {
+		l.root = andNode{l.root, parsed}
+	}
+	return nil
+}
+
+func (l *labelExpr) Type() string {
+	return "expression"
+}
+
+func (l *labelExpr) String() string {
+	if len(l.raw) == 1 {
+		return l.raw[0]
+	}
+	parts := make([]string, 0, len(l.raw))
+	for _, raw := range l.raw {
+		parts = append(parts, "("+raw+")")
+	}
+	return strings.Join(parts, " && ")
+}
+
+// Match returns true if expression is empty or evaluates to true for labels.
+func (l *labelExpr) Match(labels map[string]struct{}) bool {
+	if l.root == nil {
+		return true
+	}
+	return l.root.eval(labels)
+}
+
+type node interface {
+	eval(map[string]struct{}) bool
+}
+
+type labelNode string
+
+func (n labelNode) eval(labels map[string]struct{}) bool {
+	_, exist := labels[string(n)]
+	return exist
+}
+
+type notNode struct{ node }
+
+func (n notNode) eval(labels map[string]struct{}) bool {
+	return !n.node.eval(labels)
+}
+
+type andNode [2]node
+
+func (n andNode) eval(labels map[string]struct{}) bool {
+	return n[0].eval(labels) && n[1].eval(labels)
+}
+
+type orNode [2]node
+
+func (n orNode) eval(labels map[string]struct{}) bool {
+	return n[0].eval(labels) || n[1].eval(labels)
+}
+
+func parseLabels(expr string) (node, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.or()
+	if err != nil {
+		return nil, fmt.Errorf("parse %q: %w", expr, err)
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("parse %q: unexpected %q", expr, p.peek())
+	}
+	return root, nil
+}
+
+func isLabelRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.'
+}
+
+func tokenize(expr string) ([]string, error) {
+	var tokens []string
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == '!':
+			tokens = append(tokens, string(r))
+			i++
+		case r == ',':
+			tokens = append(tokens, "&&")
+			i++
+		case r == '&' || r == '|':
+			if i+1 >= len(runes) || runes[i+1] != r {
+				return nil, fmt.Errorf("expected %c%c at %d in %q", r, r, i, expr)
+			}
+			tokens = append(tokens, string(runes[i:i+2]))
+			i += 2
+		case isLabelRune(r):
+			start := i
+			for i < len(runes) && isLabelRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d in %q", r, i, expr)
+		}
+	}
+	return tokens, nil
+}
+
+// parser is a recursive descent parser for grammar:
+//
+//	or    = and { "||" and }
+//	and   = unary { "&&" unary }
+//	unary = "!" unary | "(" or ")" | label
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.next()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (node, error) {
+	switch token := p.next(); token {
+	case "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case "!":
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	case "(":
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing != ")" {
+			return nil, fmt.Errorf("expected ) instead of %q", closing)
+		}
+		return inner, nil
+	case ")", "&&", "||":
+		return nil, fmt.Errorf("unexpected %q", token)
+	default:
+		return labelNode(token), nil
+	}
+}
@@ -0,0 +1,89 @@
+package testcontext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func labelSet(labels ...string) map[string]struct{} {
+	rst := map[string]struct{}{}
+	for _, label := range labels {
+		rst[label] = struct{}{}
+	}
+	return rst
+}
+
+func TestLabelsMatch(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		exprs   []string
+		labels  []string
+		matched bool
+	}{
+		{desc: "empty", matched: true},
+		{desc: "blank", exprs: []string{"  "}, matched: true},
+		{desc: "label", exprs: []string{"sanity"}, labels: []string{"sanity"}, matched: true},
+		{desc: "missing label", exprs: []string{"sanity"}, labels: []string{"slow"}},
+		{desc: "not", exprs: []string{"!slow"}, labels: []string{"sanity"}, matched: true},
+		{desc: "double not", exprs: []string{"!!slow"}, labels: []string{"slow"}, matched: true},
+		{desc: "and", exprs: []string{"sanity && !slow"}, labels: []string{"sanity", "slow"}},
+		{desc: "comma", exprs: []string{"sanity,chaos"}, labels: []string{"sanity", "chaos"}, matched: true},
+		{desc: "or", exprs: []string{"longevity || chaos"}, labels: []string{"chaos"}, matched: true},
+		{desc: "and binds tighter than or", exprs: []string{"a || b && c"}, labels: []string{"a"}, matched: true},
+		{
+			desc:    "parentheses",
+			exprs:   []string{"(longevity || chaos) && !flaky"},
+			labels:  []string{"chaos", "flaky"},
+			matched: false,
+		},
+		{
+			desc:    "label runes",
+			exprs:   []string{"poet-failure_1.x"},
+			labels:  []string{"poet-failure_1.x"},
+			matched: true,
+		},
+		{desc: "repeated flags", exprs: []string{"a || b", "c"}, labels: []string{"a"}},
+		{desc: "repeated flags match", exprs: []string{"a || b", "c"}, labels: []string{"b", "c"}, matched: true},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			var expr labelExpr
+			for _, raw := range tc.exprs {
+				require.NoError(t, expr.Set(raw))
+			}
+			require.Equal(t, tc.matched, expr.Match(labelSet(tc.labels...)))
+		})
+	}
+}
+
+func TestLabelsMalformed(t *testing.T) {
+	for _, raw := range []string{
+		"a b",
+		"(a",
+		"a)",
+		"()",
+		"a ||",
+		"&& a",
+		"a & b",
+		"a | b",
+		"!",
+		"a && !",
+		"a $ b",
+	} {
+		raw := raw
+		t.Run(raw, func(t *testing.T) {
+			var expr labelExpr
+			require.Error(t, expr.Set(raw))
+			require.Empty(t, expr.String())
+		})
+	}
+}
+
+func TestLabelsString(t *testing.T) {
+	var expr labelExpr
+	require.NoError(t, expr.Set("a || b"))
+	require.Equal(t, "a || b", expr.String())
+	require.NoError(t, expr.Set("c"))
+	require.Equal(t, "(a || b) && (c)", expr.String())
+}
@@ -0,0 +1,259 @@
+package testcontext
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	reportJSON  = "report.json"
+	reportJUnit = "junit.xml"
+	suiteName   = "systest"
+)
+
+// Test statuses in the report.
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// global collection of reports from all tests in the binary.
+// reports are written every time a test completes, as there is no hook
+// that runs after all tests.
+var reports = &reportSet{}
+
+type reportSet struct {
+	mu      sync.Mutex
+	reports []*Report
+}
+
+func (s *reportSet) add(r *Report) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reports = append(s.reports, r)
+	if err := s.write(*artifacts); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write reports: %v\n", err)
+	}
+}
+
+// Event is a notable action that happened during the test, such as injected chaos.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind"`
+	Name    string    `json:"name"`
+	Message string    `json:"message,omitempty"`
+}
+
+// Invariant is a result of the check performed by the test.
+type Invariant struct {
+	Time   time.Time `json:"time"`
+	Name   string    `json:"name"`
+	Passed bool      `json:"passed"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// Report records parameters and results of the test.
+type Report struct {
+	mu sync.Mutex
+
+	Name       string            `json:"name"`
+	Status     string            `json:"status"`
+	Message    string            `json:"message,omitempty"`
+	Start      time.Time         `json:"start"`
+	Duration   time.Duration     `json:"duration"`
+	Labels     []string          `json:"labels,omitempty"`
+	Params     map[string]string `json:"params"`
+	Events     []Event           `json:"events,omitempty"`
+	Invariants []Invariant       `json:"invariants,omitempty"`
+}
+
+func newReport(name string, labels map[string]struct{}) *Report {
+	r := &Report{
+		Name:   name,
+		Start:  time.Now(),
+		Params: map[string]string{},
+	}
+	for label := range labels {
+		r.Labels = append(r.Labels, label)
+	}
+	sort.Strings(r.Labels)
+	return r
+}
+
+// Param records parameter of the test, such as cluster size or image.
+func (r *Report) Param(key string, value interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Params[key] = fmt.Sprint(value)
+}
+
+// Event records an action, such as chaos injection.
+func (r *Report) Event(kind, name, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Events = append(r.Events, Event{Time: time.Now(), Kind: kind, Name: name, Message: message})
+}
+
+// Invariant records result of the check and returns err unchanged.
+func (r *Report) Invariant(name string, err error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	inv := Invariant{Time: time.Now(), Name: name, Passed: err == nil}
+	if err != nil {
+		inv.Error = err.Error()
+	}
+	r.Invariants = append(r.Invariants, inv)
+	return err
+}
+
+func (r *Report) finish(tb testing.TB) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Duration = time.Since(r.Start)
+	switch {
+	case tb.Failed():
+		r.Status = StatusFailed
+	case tb.Skipped():
+		r.Status = StatusSkipped
+	default:
+		r.Status = StatusPassed
+	}
+}
+
+func (s *reportSet) write(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	sorted := make([]*Report, len(s.reports))
+	copy(sorted, s.reports)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	for _, r := range sorted {
+		r.mu.Lock()
+	}
+	defer func() {
+		for _, r := range sorted {
+			r.mu.Unlock()
+		}
+	}()
+	summary := struct {
+		Image     string    `json:"image"`
+		PoetImage string    `json:"poet_image"`
+		Tests     []*Report `json:"tests"`
+	}{Image: *imageFlag, PoetImage: *poetImage, Tests: sorted}
+	if err := writeJSON(filepath.Join(dir, reportJSON), &summary); err != nil {
+		return err
+	}
+	data, err := xml.MarshalIndent(junitSuites(sorted), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, reportJUnit), append([]byte(xml.Header), data...), 0o644)
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+type junitCase struct {
+	Name       string          `xml:"name,attr"`
+	Classname  string          `xml:"classname,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Failure    *junitMessage   `xml:"failure,omitempty"`
+	Skipped    *junitMessage   `xml:"skipped,omitempty"`
+	SystemOut  string          `xml:"system-out,omitempty"`
+}
+
+type junitSuite struct {
+	XMLName    xml.Name        `xml:"testsuite"`
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitCase     `xml:"testcase"`
+}
+
+type junitSuitesDoc struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+func junitSuites(sorted []*Report) junitSuitesDoc {
+	suite := junitSuite{
+		Name: suiteName,
+		Properties: []junitProperty{
+			{Name: "image", Value: *imageFlag},
+			{Name: "poet-image", Value: *poetImage},
+		},
+	}
+	var total time.Duration
+	for _, r := range sorted {
+		total += r.Duration
+		tc := junitCase{
+			Name:      r.Name,
+			Classname: suiteName,
+			Time:      seconds(r.Duration),
+		}
+		keys := make([]string, 0, len(r.Params))
+		for key := range r.Params {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			tc.Properties = append(tc.Properties, junitProperty{Name: key, Value: r.Params[key]})
+		}
+		var out strings.Builder
+		for _, ev := range r.Events {
+			fmt.Fprintf(&out, "%s %s %s %s\n", ev.Time.Format(time.RFC3339), ev.Kind, ev.Name, ev.Message)
+		}
+		var failed []string
+		for _, inv := range r.Invariants {
+			result := "passed"
+			if !inv.Passed {
+				result = "failed: " + inv.Error
+				failed = append(failed, inv.Name+": "+inv.Error)
+			}
+			fmt.Fprintf(&out, "%s invariant %s %s\n", inv.Time.Format(time.RFC3339), inv.Name, result)
+		}
+		tc.SystemOut = out.String()
+		switch r.Status {
+		case StatusFailed:
+			suite.Failures++
+			msg := &junitMessage{Message: "test failed"}
+			if len(failed) > 0 {
+				msg.Message = fmt.Sprintf("%d invariants failed", len(failed))
+				msg.Text = strings.Join(failed, "\n")
+			}
+			tc.Failure = msg
+		case StatusSkipped:
+			suite.Skipped++
+			tc.Skipped = &junitMessage{Message: r.Message}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Tests = len(suite.Cases)
+	suite.Time = seconds(total)
+	return junitSuitesDoc{Suites: []junitSuite{suite}}
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
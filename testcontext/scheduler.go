@@ -0,0 +1,83 @@
+package testcontext
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// scheduler admits parallel tests as long as the total number of pods
+// they requested is within the capacity.
+//
+// Capacity is computed lazily from -clusters and -size, as flags
+// are not parsed when package is initialized.
+type scheduler struct {
+	once     sync.Once
+	capacity int64
+	sem      *semaphore.Weighted
+
+	mu     sync.Mutex
+	shared map[string]*sharedPods
+}
+
+// sharedPods tracks admission of the shared cluster in the namespace.
+// Shared cluster is not deleted after the tests that use it (see Shared), its pods keep
+// running until the namespace is removed out of band, therefore pods are admitted once
+// and never released for the lifetime of the process.
+type sharedPods struct {
+	admitted chan struct{}
+	err      error
+}
+
+var pods = &scheduler{shared: map[string]*sharedPods{}}
+
+func (s *scheduler) init() {
+	s.once.Do(func() {
+		s.capacity = int64(*clusters) * int64(*clusterSize)
+		s.sem = semaphore.NewWeighted(s.capacity)
+	})
+}
+
+// acquire blocks until n pods can be admitted or ctx is done. Returned function must be called
+// to release them.
+func (s *scheduler) acquire(ctx context.Context, n int) (func(), error) {
+	s.init()
+	if int64(n) > s.capacity {
+		return nil, fmt.Errorf("test requested %d pods, but capacity is %d (-clusters * -size)", n, s.capacity)
+	}
+	if err := s.sem.Acquire(ctx, int64(n)); err != nil {
+		return nil, fmt.Errorf("wait for capacity for %d pods: %w", n, err)
+	}
+	return func() { s.sem.Release(int64(n)) }, nil
+}
+
+// acquireShared admits n pods for the shared cluster in the namespace once for all tests
+// that use it. Admitted pods are never released, as the shared cluster outlives the tests.
+func (s *scheduler) acquireShared(ctx context.Context, namespace string, n int) error {
+	s.mu.Lock()
+	shared, exist := s.shared[namespace]
+	if !exist {
+		shared = &sharedPods{admitted: make(chan struct{})}
+		s.shared[namespace] = shared
+	}
+	s.mu.Unlock()
+
+	if !exist {
+		_, shared.err = s.acquire(ctx, n)
+		if shared.err != nil {
+			// next test will try again
+			s.mu.Lock()
+			delete(s.shared, namespace)
+			s.mu.Unlock()
+		}
+		close(shared.admitted)
+	}
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("wait for shared cluster in %s: %w", namespace, ctx.Err())
+	case <-shared.admitted:
+	}
+	return shared.err
+}
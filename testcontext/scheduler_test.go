@@ -0,0 +1,64 @@
+package testcontext
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/semaphore"
+)
+
+func newTestScheduler(capacity int64) *scheduler {
+	s := &scheduler{shared: map[string]*sharedPods{}}
+	s.once.Do(func() {
+		s.capacity = capacity
+		s.sem = semaphore.NewWeighted(capacity)
+	})
+	return s
+}
+
+func TestSchedulerSharedReserved(t *testing.T) {
+	s := newTestScheduler(10)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, s.acquireShared(ctx, "shared-a", 8))
+	require.NoError(t, s.acquireShared(ctx, "shared-a", 8))
+	require.False(t, s.sem.TryAcquire(3), "shared pods must be admitted once")
+
+	// shared cluster outlives the tests, its pods are never released
+	short, cancelShort := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancelShort()
+	_, err := s.acquire(short, 8)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release, err := s.acquire(ctx, 2)
+	require.NoError(t, err)
+	release()
+}
+
+func TestSchedulerSharedTimeout(t *testing.T) {
+	s := newTestScheduler(10)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	release, err := s.acquire(ctx, 5)
+	require.NoError(t, err)
+
+	short, cancelShort := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancelShort()
+	require.ErrorIs(t, s.acquireShared(short, "shared-a", 8), context.DeadlineExceeded)
+	require.Empty(t, s.shared)
+
+	release()
+	require.NoError(t, s.acquireShared(ctx, "shared-a", 8))
+}
+
+func TestSchedulerCapacityExceeded(t *testing.T) {
+	s := newTestScheduler(10)
+	_, err := s.acquire(context.Background(), 11)
+	require.Error(t, err)
+	require.Error(t, s.acquireShared(context.Background(), "shared-a", 11))
+	require.Empty(t, s.shared)
+}
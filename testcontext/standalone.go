@@ -0,0 +1,84 @@
+package testcontext
+
+import (
+	"context"
+	"fmt"
+
+	chaosoperatorv1alpha1 "github.com/chaos-mesh/chaos-mesh/api/v1alpha1"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// restConfig uses in-cluster config if available, otherwise
+// falls back to kubeconfig (KUBECONFIG or ~/.kube/config).
+func restConfig() (*rest.Config, error) {
+	config, err := rest.InClusterConfig()
+	if err == nil {
+		return config, nil
+	}
+	config, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("load kubeconfig: %w", err)
+	}
+	return config, nil
+}
+
+// Standalone creates context outside of the test, for example for the command line tool.
+// It uses the same flags as New, namespace is taken from -namespace and may be empty.
+// Namespace is not created or deleted by Standalone.
+func Standalone(ctx context.Context) (*Context, error) {
+	config, err := restConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	scheme := runtime.NewScheme()
+	if err := chaosoperatorv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	generic, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	logcfg := zap.NewDevelopmentConfig()
+	logcfg.Level = zap.NewAtomicLevelAt(*logLevel)
+	logger, err := logcfg.Build()
+	if err != nil {
+		return nil, err
+	}
+	return &Context{
+		Context:           ctx,
+		Namespace:         *namespaceFlag,
+		BootstrapDuration: *bootstrapDuration,
+		Client:            clientset,
+		Config:            config,
+		Generic:           generic,
+		ClusterSize:       *clusterSize,
+		Image:             *imageFlag,
+		PoetImage:         *poetImage,
+		NodeSelector:      nodeSelector,
+		Log:               logger.Sugar(),
+		Report:            newReport("standalone", nil),
+		chaos:             &chaosRegistry{},
+	}, nil
+}
+
+// Create creates namespace for the context. Namespace is not deleted by the sweeper.
+func (c *Context) Create() error {
+	return deployNamespace(c, 0)
+}
+
+// Delete deletes namespace of the context with all objects in it.
+func (c *Context) Delete() error {
+	return deleteNamespace(c)
+}
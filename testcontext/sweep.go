@@ -0,0 +1,103 @@
+package testcontext
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	apimetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	ownerLabel   = "systest.spacemesh.io/owner"
+	createdLabel = "systest.spacemesh.io/created"
+	ttlLabel     = "systest.spacemesh.io/ttl"
+)
+
+// namespaceLabels for the namespace created by the test. Namespaces without ttl
+// (kept or shared) are never deleted by the sweeper.
+func namespaceLabels(now time.Time, ttl time.Duration) map[string]string {
+	owner, err := os.Hostname()
+	if err != nil {
+		owner = "unknown"
+	}
+	labels := map[string]string{
+		ownerLabel:   owner,
+		createdLabel: strconv.FormatInt(now.Unix(), 10),
+	}
+	if ttl > 0 {
+		labels[ttlLabel] = strconv.FormatInt(int64(ttl/time.Second), 10)
+	}
+	return labels
+}
+
+// expired returns true if ttl of the namespace expired.
+func expired(ns *v1.Namespace, now time.Time) (bool, error) {
+	created, err := strconv.ParseInt(ns.Labels[createdLabel], 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("parse %s of namespace %s: %w", createdLabel, ns.Name, err)
+	}
+	ttl, err := strconv.ParseInt(ns.Labels[ttlLabel], 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("parse %s of namespace %s: %w", ttlLabel, ns.Name, err)
+	}
+	return now.After(time.Unix(created+ttl, 0)), nil
+}
+
+// sweep deletes namespaces that were created by tests and whose ttl expired.
+// Namespaces with malformed labels are logged and skipped. Returns names of the deleted namespaces.
+func sweep(ctx context.Context, log *zap.SugaredLogger, client kubernetes.Interface, now time.Time) ([]string, error) {
+	namespaces, err := client.CoreV1().Namespaces().List(ctx, apimetav1.ListOptions{
+		LabelSelector: ttlLabel,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list namespaces: %w", err)
+	}
+	var deleted []string
+	for i := range namespaces.Items {
+		ns := &namespaces.Items[i]
+		if ns.Status.Phase == v1.NamespaceTerminating {
+			continue
+		}
+		exp, err := expired(ns, now)
+		if err != nil {
+			log.Warnw("skipping namespace with malformed labels", "namespace", ns.Name, "error", err)
+			continue
+		}
+		if !exp {
+			continue
+		}
+		if err := client.CoreV1().Namespaces().Delete(ctx, ns.Name, apimetav1.DeleteOptions{}); err != nil {
+			return deleted, fmt.Errorf("delete namespace %s: %w", ns.Name, err)
+		}
+		deleted = append(deleted, ns.Name)
+	}
+	return deleted, nil
+}
+
+var sweepOnce sync.Once
+
+// sweepOnStart runs sweeper once per process, before the first test deploys namespace.
+func sweepOnStart(cctx *Context) {
+	sweepOnce.Do(func() {
+		deleted, err := sweep(cctx, cctx.Log, cctx.Client, time.Now())
+		if err != nil {
+			cctx.Log.Warnw("failed to sweep expired namespaces", "error", err)
+		}
+		if len(deleted) > 0 {
+			cctx.Log.Infow("deleted expired namespaces", "namespaces", deleted)
+		}
+	})
+}
+
+// Sweep deletes namespaces that were created by tests and whose ttl expired.
+// Returns names of the deleted namespaces.
+func (c *Context) Sweep() ([]string, error) {
+	return sweep(c, c.Log, c.Client, time.Now())
+}
@@ -0,0 +1,46 @@
+package testcontext
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	v1 "k8s.io/api/core/v1"
+	apimetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testNamespace(name string, labels map[string]string) runtime.Object {
+	return &v1.Namespace{ObjectMeta: apimetav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestSweep(t *testing.T) {
+	now := time.Now()
+	created := strconv.FormatInt(now.Add(-time.Hour).Unix(), 10)
+	client := fake.NewSimpleClientset(
+		testNamespace("expired", namespaceLabels(now.Add(-time.Hour), time.Minute)),
+		testNamespace("malformed-ttl", map[string]string{createdLabel: created, ttlLabel: "1m"}),
+		testNamespace("malformed-created", map[string]string{createdLabel: "yesterday", ttlLabel: "60"}),
+		testNamespace("also-expired", namespaceLabels(now.Add(-time.Hour), 30*time.Minute)),
+		testNamespace("active", namespaceLabels(now.Add(-time.Hour), 2*time.Hour)),
+		testNamespace("kept", namespaceLabels(now.Add(-time.Hour), 0)),
+	)
+	deleted, err := sweep(context.Background(), zaptest.NewLogger(t).Sugar(), client, now)
+	require.NoError(t, err)
+	sort.Strings(deleted)
+	require.Equal(t, []string{"also-expired", "expired"}, deleted)
+
+	namespaces, err := client.CoreV1().Namespaces().List(context.Background(), apimetav1.ListOptions{})
+	require.NoError(t, err)
+	var remaining []string
+	for _, ns := range namespaces.Items {
+		remaining = append(remaining, ns.Name)
+	}
+	sort.Strings(remaining)
+	require.Equal(t, []string{"active", "kept", "malformed-created", "malformed-ttl"}, remaining)
+}
@@ -4,16 +4,18 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"strings"
 	"time"
 
-	"github.com/golang/protobuf/ptypes/empty"
 	spacemeshv1 "github.com/spacemeshos/api/release/go/spacemesh/v1"
 	"github.com/spacemeshos/ed25519"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/spacemeshos/go-spacemesh/systest/chaos"
 	"github.com/spacemeshos/go-spacemesh/systest/cluster"
+	"github.com/spacemeshos/go-spacemesh/systest/stream"
 	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+	"github.com/spacemeshos/go-spacemesh/systest/topology"
 )
 
 type transaction struct {
@@ -82,39 +84,13 @@ func extractNames(nodes ...*cluster.NodeClient) []string {
 func collectLayers(ctx context.Context, eg *errgroup.Group, client *cluster.NodeClient,
 	collector func(*spacemeshv1.LayerStreamResponse) (bool, error)) {
 	eg.Go(func() error {
-		meshapi := spacemeshv1.NewMeshServiceClient(client)
-		layers, err := meshapi.LayerStream(ctx, &spacemeshv1.LayerStreamRequest{})
-		if err != nil {
-			return err
-		}
-		for {
-			layer, err := layers.Recv()
-			if err != nil {
-				return err
-			}
-			if cont, err := collector(layer); !cont {
-				return err
-			}
-		}
+		return stream.Layers(ctx, client, collector)
 	})
 }
 
 func collectProposals(ctx context.Context, eg *errgroup.Group, client *cluster.NodeClient, collector func(*spacemeshv1.Proposal) (bool, error)) {
 	eg.Go(func() error {
-		dbg := spacemeshv1.NewDebugServiceClient(client)
-		proposals, err := dbg.ProposalsStream(ctx, &empty.Empty{})
-		if err != nil {
-			return err
-		}
-		for {
-			proposal, err := proposals.Recv()
-			if err != nil {
-				return err
-			}
-			if cont, err := collector(proposal); !cont {
-				return err
-			}
-		}
+		return stream.Proposals(ctx, client, collector)
 	})
 }
 
@@ -160,3 +136,24 @@ func waitAll(tctx *testcontext.Context, cl *cluster.Cluster) error {
 	}
 	return eg.Wait()
 }
+
+func logTopology(tctx *testcontext.Context, cl *cluster.Cluster) error {
+	clients := make([]*cluster.NodeClient, 0, cl.Total())
+	for i := 0; i < cl.Total(); i++ {
+		clients = append(clients, cl.Client(i))
+	}
+	graph, err := topology.Build(tctx, topology.TCPSource(tctx, clients...), clients...)
+	if err != nil {
+		return err
+	}
+	var dot strings.Builder
+	if err := graph.WriteDOT(&dot); err != nil {
+		return err
+	}
+	tctx.Log.Debugw("p2p topology",
+		"degrees", graph.Degrees(),
+		"islands", len(graph.Islands()),
+		"dot", dot.String(),
+	)
+	return nil
+}
@@ -3,6 +3,7 @@ package tests
 import (
 	"context"
 	"strings"
+	"sync"
 	"testing"
 
 	spacemeshv1 "github.com/spacemeshos/api/release/go/spacemesh/v1"
@@ -12,46 +13,43 @@ import (
 
 	"github.com/spacemeshos/go-spacemesh/systest/chaos"
 	"github.com/spacemeshos/go-spacemesh/systest/cluster"
+	"github.com/spacemeshos/go-spacemesh/systest/stream"
 	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
 )
 
 func TestAddNodes(t *testing.T) {
 	tctx := testcontext.New(t, testcontext.Labels("sanity"))
 
-	const (
-		epochBeforeJoin = 5
-		lastEpoch       = 7
+	cl := cluster.New(tctx)
+	timing, err := cl.Timing()
+	require.NoError(t, err)
 
-		beforeAdding = 11
+	var (
+		beforeAdding = timing.LastLayer(2)
 		// 4 epochs to fully join:
-		// sync finishes at layer 16
-		// atx published layer 20, in the next epoch node will participate in beacon
+		// sync finishes in the next epoch
+		// atx published in the epoch after, in the next epoch node will participate in beacon
 		// after beacon computed - node will build proposals
-		fullyJoined = beforeAdding + 16
-		lastLayer   = fullyJoined + 8
-	)
+		fullyJoined = beforeAdding + 4*timing.LayersPerEpoch
+		lastLayer   = fullyJoined + 2*timing.LayersPerEpoch
 
-	cl := cluster.New(tctx)
+		epochBeforeJoin = uint64(timing.EpochOf(fullyJoined) - 1)
+		lastEpoch       = uint64(timing.EpochOf(lastLayer) - 1)
+	)
 
 	require.NoError(t, cl.AddBootnodes(tctx, 2))
 	require.NoError(t, cl.AddPoet(tctx))
 	addedLater := int(0.2 * float64(tctx.ClusterSize))
 	require.NoError(t, cl.AddSmeshers(tctx, tctx.ClusterSize-2-addedLater))
 
+	require.NoError(t, timing.WaitLayer(tctx, beforeAdding))
+	tctx.Log.Debugw("adding new smeshers",
+		"n", addedLater,
+		"layer", beforeAdding,
+	)
+	require.NoError(t, cl.AddSmeshers(tctx, addedLater))
+
 	var eg errgroup.Group
-	{
-		collectLayers(tctx, &eg, cl.Client(0), func(layer *spacemeshv1.LayerStreamResponse) (bool, error) {
-			if layer.Layer.Number.Number >= beforeAdding {
-				tctx.Log.Debugw("adding new smeshers",
-					"n", addedLater,
-					"layer", layer.Layer.Number,
-				)
-				return false, cl.AddSmeshers(tctx, addedLater)
-			}
-			return true, nil
-		})
-	}
-	require.NoError(t, eg.Wait())
 
 	created := make([][]*spacemeshv1.Proposal, cl.Total())
 	for i := 0; i < cl.Total(); i++ {
@@ -88,7 +86,7 @@ func TestAddNodes(t *testing.T) {
 	for epoch := uint64(2) + 1; epoch <= epochBeforeJoin; epoch++ {
 		require.Len(t, unique[epoch], cl.Total()-addedLater, "epoch=%d", epoch)
 	}
-	for epoch := uint64(epochBeforeJoin) + 1; epoch <= lastEpoch; epoch++ {
+	for epoch := epochBeforeJoin + 1; epoch <= lastEpoch; epoch++ {
 		require.Len(t, unique[epoch], cl.Total(), "epoch=%d", epoch)
 	}
 }
@@ -96,52 +94,97 @@ func TestAddNodes(t *testing.T) {
 func TestFailedNodes(t *testing.T) {
 	tctx := testcontext.New(t, testcontext.Labels("sanity"))
 
-	const (
-		failAt    = 15
-		lastLayer = failAt + 8
-	)
-
 	cl, err := cluster.Default(tctx)
 	require.NoError(t, err)
 
-	failed := int(0.6 * float64(tctx.ClusterSize))
+	timing, err := cl.Timing()
+	require.NoError(t, err)
+	var (
+		failAt    = timing.LastLayer(3)
+		lastLayer = failAt + 2*timing.LayersPerEpoch
+	)
 
+	const name = "fail60percent"
+	var (
+		mu sync.Mutex
+		// failed nodes are chosen by chaos-mesh, they are known once chaos is injected
+		failed  = map[string]struct{}{}
+		cancels = make([]context.CancelFunc, cl.Total())
+	)
 	eg, ctx := errgroup.WithContext(tctx)
+	// chaos is scheduled by the bootnode, as only smeshers are failed
 	scheduleChaos(ctx, eg, cl.Client(0), failAt, lastLayer, func(ctx context.Context) (error, chaos.Teardown) {
-		names := []string{}
-		for i := 1; i <= failed; i++ {
-			names = append(names, cl.Client(cl.Total()-i).Name)
+		err, teardown := chaos.FailSelected(tctx, name, chaos.Smeshers().Percent(60))
+		if err != nil {
+			return err, nil
+		}
+		names, err := chaos.Selected(tctx, name)
+		if err != nil {
+			return err, teardown
+		}
+		tctx.Log.Debugw("failed nodes", "names", strings.Join(names, ","))
+		mu.Lock()
+		defer mu.Unlock()
+		for _, pod := range names {
+			failed[pod] = struct{}{}
 		}
-		tctx.Log.Debugw("failing nodes", "names", strings.Join(names, ","))
-		return chaos.Fail(tctx, "fail60percent", names...)
+		// layers from failed nodes are not compared
+		for i := 0; i < cl.Total(); i++ {
+			if _, exist := failed[cl.Client(i).Name]; exist {
+				cancels[i]()
+			}
+		}
+		return nil, teardown
 	})
 
 	hashes := make([]map[uint32]string, cl.Total())
-	for i := 0; i < cl.Total(); i++ {
+	for i := range hashes {
 		hashes[i] = map[uint32]string{}
 	}
-	for i := 0; i < cl.Total()-failed; i++ {
+	for i := 0; i < cl.Total(); i++ {
 		i := i
 		client := cl.Client(i)
-		collectLayers(ctx, eg, client, func(layer *spacemeshv1.LayerStreamResponse) (bool, error) {
-			if layer.Layer.Status == spacemeshv1.Layer_LAYER_STATUS_CONFIRMED {
-				tctx.Log.Debugw("confirmed layer",
-					"client", client.Name,
-					"layer", layer.Layer.Number.Number,
-					"hash", prettyHex(layer.Layer.Hash),
-				)
-				if layer.Layer.Number.Number == lastLayer {
-					return false, nil
+		nodeCtx, cancel := context.WithCancel(ctx)
+		cancels[i] = cancel
+		eg.Go(func() error {
+			err := stream.Layers(nodeCtx, client, func(layer *spacemeshv1.LayerStreamResponse) (bool, error) {
+				if layer.Layer.Status == spacemeshv1.Layer_LAYER_STATUS_CONFIRMED {
+					tctx.Log.Debugw("confirmed layer",
+						"client", client.Name,
+						"layer", layer.Layer.Number.Number,
+						"hash", prettyHex(layer.Layer.Hash),
+					)
+					if layer.Layer.Number.Number == lastLayer {
+						return false, nil
+					}
+					mu.Lock()
+					hashes[i][layer.Layer.Number.Number] = prettyHex(layer.Layer.Hash)
+					mu.Unlock()
 				}
-				hashes[i][layer.Layer.Number.Number] = prettyHex(layer.Layer.Hash)
+				return true, nil
+			})
+			if nodeCtx.Err() != nil && ctx.Err() == nil {
+				// node was failed
+				return nil
 			}
-			return true, nil
+			return err
 		})
 	}
 	require.NoError(t, eg.Wait())
-	reference := hashes[0]
-	for i, tested := range hashes[1 : cl.Total()-failed] {
-		assert.Equal(t, reference, tested, "client=%d", i)
+	for _, cancel := range cancels {
+		cancel()
+	}
+	require.NotEmpty(t, failed, "chaos didn't select any smesher")
+	var reference map[uint32]string
+	for i := 0; i < cl.Total(); i++ {
+		if _, exist := failed[cl.Client(i).Name]; exist {
+			continue
+		}
+		if reference == nil {
+			reference = hashes[i]
+			continue
+		}
+		assert.Equal(t, reference, hashes[i], "client=%s", cl.Client(i).Name)
 	}
-	require.NoError(t, waitAll(tctx, cl))
+	require.NoError(t, tctx.Report.Invariant("failed nodes recovered", waitAll(tctx, cl)))
 }
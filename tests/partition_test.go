@@ -26,7 +26,9 @@ func TestPartition(t *testing.T) {
 	)
 
 	cl, err := cluster.Default(tctx,
-		cluster.WithSmesherFlag(cluster.RerunInterval(2*time.Minute)),
+		cluster.WithConfig(func(cfg *cluster.Config) {
+			cfg.Tortoise.RerunInterval = cluster.Duration(2 * time.Minute)
+		}),
 	)
 	require.NoError(t, err)
 
@@ -61,6 +63,9 @@ func TestPartition(t *testing.T) {
 		})
 	}
 	require.NoError(t, eg.Wait())
+	if err := logTopology(tctx, cl); err != nil {
+		tctx.Log.Warnw("failed to collect p2p topology", "error", err)
+	}
 	reference := hashes[0]
 	for i, tested := range hashes[1:] {
 		assert.Equal(t, reference, tested, "client=%s", cl.Client(i+1).Name)
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	spacemeshv1 "github.com/spacemeshos/api/release/go/spacemesh/v1"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/spacemeshos/go-spacemesh/systest/chaos"
+	"github.com/spacemeshos/go-spacemesh/systest/cluster"
+	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+)
+
+func TestPoetFailure(t *testing.T) {
+	tctx := testcontext.New(t, testcontext.Labels("chaos", "poet"))
+
+	cl, err := cluster.Default(tctx)
+	require.NoError(t, err)
+
+	timing, err := cl.Timing()
+	require.NoError(t, err)
+	var (
+		failAt    = timing.FirstLayer(2)
+		restoreAt = timing.FirstLayer(4)
+		// poet is down for the whole round that precedes atx publication in these epochs
+		outage = [2]uint32{timing.EpochOf(failAt) + 1, timing.EpochOf(restoreAt) - 1}
+		// poet round that starts after restore must complete before atxs are published
+		recovered = timing.EpochOf(restoreAt) + 2
+		lastLayer = timing.FirstLayer(recovered + 1)
+	)
+
+	eg, ctx := errgroup.WithContext(tctx)
+	scheduleChaos(ctx, eg, cl.Client(0), failAt, restoreAt, func(ctx context.Context) (error, chaos.Teardown) {
+		return chaos.FailPoet(tctx, "fail-poet")
+	})
+
+	// smesher ids that published atx, by epoch of the layer that atx belongs to
+	published := map[uint32]map[string]struct{}{}
+	collectLayers(ctx, eg, cl.Client(0), func(layer *spacemeshv1.LayerStreamResponse) (bool, error) {
+		number := layer.Layer.Number.Number
+		if number == lastLayer {
+			return false, nil
+		}
+		epoch := timing.EpochOf(number)
+		if _, exist := published[epoch]; !exist {
+			published[epoch] = map[string]struct{}{}
+		}
+		for _, atx := range layer.Layer.Activations {
+			published[epoch][prettyHex(atx.SmesherId.Id)] = struct{}{}
+		}
+		return true, nil
+	})
+	require.NoError(t, eg.Wait())
+	for epoch := uint32(0); epoch <= recovered; epoch++ {
+		tctx.Log.Debugw("published atxs", "epoch", epoch, "count", len(published[epoch]))
+	}
+	var outageErr error
+	for epoch := outage[0]; epoch <= outage[1]; epoch++ {
+		if n := len(published[epoch]); n >= cl.Total() {
+			outageErr = fmt.Errorf("expected less than %d atxs in epoch %d while poet failed, got %d",
+				cl.Total(), epoch, n)
+			break
+		}
+	}
+	require.NoError(t, tctx.Report.Invariant("atxs disrupted while poet failed", outageErr))
+	var recoveryErr error
+	if n := len(published[recovered]); n != cl.Total() {
+		recoveryErr = fmt.Errorf("expected %d atxs in epoch %d, got %d", cl.Total(), recovered, n)
+	}
+	require.NoError(t, tctx.Report.Invariant("atxs published after poet recovered", recoveryErr))
+}
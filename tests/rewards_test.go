@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/systest/cluster"
+	"github.com/spacemeshos/go-spacemesh/systest/rewards"
+	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+)
+
+func TestRewards(t *testing.T) {
+	tctx := testcontext.New(t, testcontext.Labels("sanity"))
+
+	cl, err := cluster.Default(tctx)
+	require.NoError(t, err)
+
+	nodes := []*cluster.NodeClient{}
+	for i := 0; i < cl.Total(); i++ {
+		nodes = append(nodes, cl.Client(i))
+	}
+	require.NoError(t, rewards.SetCoinbase(tctx, nodes...))
+
+	timing, err := cl.Timing()
+	require.NoError(t, err)
+	var (
+		// proposals in the current epoch may have been created before coinbase was set
+		first = timing.FirstLayer(timing.EpochOf(timing.CurrentLayer(time.Now())) + 2)
+		last  = timing.LastLayer(timing.EpochOf(first) + 1)
+	)
+	collector := rewards.NewCollector(nodes...)
+	// rewards are computed after layer is applied, therefore collector runs for one more epoch
+	ctx, cancel := context.WithDeadline(tctx, timing.LayerStart(last+timing.LayersPerEpoch))
+	defer cancel()
+	require.NoError(t, collector.Run(ctx))
+	require.NoError(t, tctx.Report.Invariant("rewards are proportional to eligibilities",
+		collector.Verify(first, last)))
+}
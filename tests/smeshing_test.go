@@ -2,6 +2,7 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"sort"
 	"testing"
 
@@ -10,6 +11,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/spacemeshos/go-spacemesh/systest/cluster"
+	"github.com/spacemeshos/go-spacemesh/systest/stream"
 	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
 )
 
@@ -31,42 +33,50 @@ func TestSmeshing(t *testing.T) {
 func testSmeshing(t *testing.T, tctx *testcontext.Context, cl *cluster.Cluster) {
 	const limit = 15
 
-	createdch := make(chan *spacemeshv1.Proposal, cl.Total()*limit)
-	includedAll := make([]map[uint32][]*spacemeshv1.Proposal, cl.Total())
+	var (
+		created     = map[uint32][]*spacemeshv1.Proposal{}
+		beacons     = map[uint64]map[string]struct{}{}
+		includedAll = make([]map[uint32][]*spacemeshv1.Proposal, cl.Total())
+		index       = map[string]int{}
+		nodes       = []*cluster.NodeClient{}
+		// nodes that reported proposal after the limit
+		passed = map[string]struct{}{}
+	)
 	for i := 0; i < cl.Total(); i++ {
 		includedAll[i] = map[uint32][]*spacemeshv1.Proposal{}
+		index[cl.Client(i).Name] = i
+		nodes = append(nodes, cl.Client(i))
 	}
 
-	eg, ctx := errgroup.WithContext(tctx)
-	for i := 0; i < cl.Total(); i++ {
-		i := i
-		client := cl.Client(i)
-		collectProposals(ctx, eg, cl.Client(i), func(proposal *spacemeshv1.Proposal) (bool, error) {
-			tctx.Log.Debugw("received proposal event",
-				"client", client.Name,
-				"layer", proposal.Layer.Number,
-				"smesher", prettyHex(proposal.Smesher.Id),
-				"eligibilities", len(proposal.Eligibilities),
-				"status", spacemeshv1.Proposal_Status_name[int32(proposal.Status)],
-			)
-			if proposal.Layer.Number > limit {
-				return false, nil
-			}
-			if proposal.Status == spacemeshv1.Proposal_Created {
-				createdch <- proposal
-			} else {
-				includedAll[i][proposal.Layer.Number] = append(includedAll[i][proposal.Layer.Number], proposal)
-			}
+	bus := stream.NewBus()
+	sub := bus.Subscribe(stream.ProposalEvents())
+	ctx, cancel := context.WithCancel(tctx)
+	defer cancel()
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		return bus.Run(ctx, nodes, stream.ProposalsOpener)
+	})
+	err := sub.Watch(ctx, func(ev *stream.Event) (bool, error) {
+		if _, exist := passed[ev.Node]; exist {
 			return true, nil
-		})
-	}
-
-	require.NoError(t, eg.Wait())
-	close(createdch)
-
-	created := map[uint32][]*spacemeshv1.Proposal{}
-	beacons := map[uint64]map[string]struct{}{}
-	for proposal := range createdch {
+		}
+		proposal := ev.Proposal()
+		tctx.Log.Debugw("received proposal event",
+			"client", ev.Node,
+			"layer", proposal.Layer.Number,
+			"smesher", prettyHex(proposal.Smesher.Id),
+			"eligibilities", len(proposal.Eligibilities),
+			"status", spacemeshv1.Proposal_Status_name[int32(proposal.Status)],
+		)
+		if proposal.Layer.Number > limit {
+			passed[ev.Node] = struct{}{}
+			return len(passed) < cl.Total(), nil
+		}
+		if proposal.Status != spacemeshv1.Proposal_Created {
+			included := includedAll[index[ev.Node]]
+			included[proposal.Layer.Number] = append(included[proposal.Layer.Number], proposal)
+			return true, nil
+		}
 		created[proposal.Layer.Number] = append(created[proposal.Layer.Number], proposal)
 		if edata := proposal.GetData(); edata != nil {
 			if _, exist := beacons[proposal.Epoch.Value]; !exist {
@@ -74,7 +84,13 @@ func testSmeshing(t *testing.T, tctx *testcontext.Context, cl *cluster.Cluster)
 			}
 			beacons[proposal.Epoch.Value][prettyHex(edata.Beacon)] = struct{}{}
 		}
-	}
+		return true, nil
+	})
+	cancel()
+	// bus error is checked first, as subscription fails with the same error
+	require.NoError(t, eg.Wait())
+	require.NoError(t, err)
+
 	requireEqualEligibilities(t, created)
 	for layer := range created {
 		sort.Slice(created[layer], func(i, j int) bool {
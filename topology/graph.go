@@ -0,0 +1,201 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/spacemeshos/go-spacemesh/systest/cluster"
+)
+
+// Source returns ids of the peers that are connected to the node.
+type Source func(ctx context.Context, node *cluster.NodeClient) ([]string, error)
+
+// Graph of p2p connections keyed by Node.ID. Connections are undirected.
+type Graph struct {
+	names map[string]string
+	edges map[string]map[string]struct{}
+}
+
+// Build queries peers of every node using source and builds a graph from them.
+// Peers that are not in the nodes list are ignored.
+func Build(ctx context.Context, source Source, nodes ...*cluster.NodeClient) (*Graph, error) {
+	g := &Graph{
+		names: map[string]string{},
+		edges: map[string]map[string]struct{}{},
+	}
+	for _, node := range nodes {
+		g.names[node.ID] = node.Name
+		g.edges[node.ID] = map[string]struct{}{}
+	}
+	peers := make([][]string, len(nodes))
+	eg, ctx := errgroup.WithContext(ctx)
+	for i, node := range nodes {
+		i, node := i, node
+		eg.Go(func() error {
+			rst, err := source(ctx, node)
+			if err != nil {
+				return fmt.Errorf("peers of %s: %w", node.Name, err)
+			}
+			peers[i] = rst
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	for i, node := range nodes {
+		for _, peer := range peers[i] {
+			g.connect(node.ID, peer)
+		}
+	}
+	return g, nil
+}
+
+func (g *Graph) connect(a, b string) {
+	if a == b {
+		return
+	}
+	if _, exist := g.edges[a]; !exist {
+		return
+	}
+	if _, exist := g.edges[b]; !exist {
+		return
+	}
+	g.edges[a][b] = struct{}{}
+	g.edges[b][a] = struct{}{}
+}
+
+func (g *Graph) ids() []string {
+	var rst []string
+	for id := range g.edges {
+		rst = append(rst, id)
+	}
+	sort.Slice(rst, func(i, j int) bool {
+		return g.names[rst[i]] < g.names[rst[j]]
+	})
+	return rst
+}
+
+// Name returns name of the node with id.
+func (g *Graph) Name(id string) string {
+	return g.names[id]
+}
+
+// Peers returns ids of the peers connected to the node with id.
+func (g *Graph) Peers(id string) []string {
+	var rst []string
+	for peer := range g.edges[id] {
+		rst = append(rst, peer)
+	}
+	sort.Strings(rst)
+	return rst
+}
+
+// Degree returns number of peers connected to the node with id.
+func (g *Graph) Degree(id string) int {
+	return len(g.edges[id])
+}
+
+// Degrees returns degree distribution, number of nodes for every degree.
+func (g *Graph) Degrees() map[int]int {
+	rst := map[int]int{}
+	for id := range g.edges {
+		rst[g.Degree(id)]++
+	}
+	return rst
+}
+
+// Islands returns connected components of the graph as lists of node ids.
+// Largest component is first.
+func (g *Graph) Islands() [][]string {
+	visited := map[string]struct{}{}
+	var islands [][]string
+	for _, start := range g.ids() {
+		if _, exist := visited[start]; exist {
+			continue
+		}
+		visited[start] = struct{}{}
+		island := []string{}
+		queue := []string{start}
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			island = append(island, id)
+			for peer := range g.edges[id] {
+				if _, exist := visited[peer]; !exist {
+					visited[peer] = struct{}{}
+					queue = append(queue, peer)
+				}
+			}
+		}
+		islands = append(islands, island)
+	}
+	sort.SliceStable(islands, func(i, j int) bool {
+		return len(islands[i]) > len(islands[j])
+	})
+	return islands
+}
+
+// Connected returns an error if graph has more than one island.
+func (g *Graph) Connected() error {
+	islands := g.Islands()
+	if len(islands) <= 1 {
+		return nil
+	}
+	parts := []string{}
+	for _, island := range islands {
+		names := []string{}
+		for _, id := range island {
+			names = append(names, g.names[id])
+		}
+		sort.Strings(names)
+		parts = append(parts, "["+strings.Join(names, ", ")+"]")
+	}
+	return fmt.Errorf("graph has %d islands: %s", len(islands), strings.Join(parts, " "))
+}
+
+// MinDegree returns an error listing nodes with less than min peers.
+func (g *Graph) MinDegree(min int) error {
+	var failed []string
+	for _, id := range g.ids() {
+		if degree := g.Degree(id); degree < min {
+			failed = append(failed, fmt.Sprintf("%s=%d", g.names[id], degree))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("nodes with less than %d peers: %s", min, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// WriteDOT writes graph in graphviz dot format.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var buf strings.Builder
+	buf.WriteString("graph p2p {\n")
+	for _, id := range g.ids() {
+		fmt.Fprintf(&buf, "  %q [label=%q];\n", id, fmt.Sprintf("%s\n%s", g.names[id], shortID(id)))
+	}
+	for _, id := range g.ids() {
+		for _, peer := range g.Peers(id) {
+			if g.names[id] < g.names[peer] {
+				fmt.Fprintf(&buf, "  %q -- %q;\n", id, peer)
+			}
+		}
+	}
+	buf.WriteString("}\n")
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+func shortID(id string) string {
+	const size = 8
+	if len(id) <= size {
+		return id
+	}
+	return id[len(id)-size:]
+}
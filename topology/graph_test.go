@@ -0,0 +1,75 @@
+package topology
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/spacemeshos/go-spacemesh/systest/cluster"
+)
+
+func TestIslands(t *testing.T) {
+	nodes := []*cluster.NodeClient{}
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		nodes = append(nodes, &cluster.NodeClient{Node: cluster.Node{Name: name, ID: "id-" + name}})
+	}
+	for _, tc := range []struct {
+		desc    string
+		peers   map[string][]string
+		islands [][]string
+	}{
+		{
+			desc: "connected",
+			peers: map[string][]string{
+				"a": {"id-b"},
+				"b": {"id-c"},
+				"c": {"id-d", "id-e"},
+			},
+			islands: [][]string{{"id-a", "id-b", "id-c", "id-d", "id-e"}},
+		},
+		{
+			desc: "one direction is enough",
+			peers: map[string][]string{
+				"e": {"id-a", "id-b", "id-c", "id-d"},
+			},
+			islands: [][]string{{"id-a", "id-b", "id-c", "id-d", "id-e"}},
+		},
+		{
+			desc: "partitioned",
+			peers: map[string][]string{
+				"a": {"id-b"},
+				"c": {"id-d", "id-e"},
+			},
+			islands: [][]string{{"id-c", "id-d", "id-e"}, {"id-a", "id-b"}},
+		},
+		{
+			desc: "isolated",
+			peers: map[string][]string{
+				"a": {"id-b", "id-c", "id-d", "id-a"},
+				"e": {"id-unknown"},
+			},
+			islands: [][]string{{"id-a", "id-b", "id-c", "id-d"}, {"id-e"}},
+		},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			source := func(_ context.Context, node *cluster.NodeClient) ([]string, error) {
+				return tc.peers[node.Name], nil
+			}
+			g, err := Build(context.Background(), source, nodes...)
+			require.NoError(t, err)
+			islands := g.Islands()
+			for _, island := range islands {
+				sort.Strings(island)
+			}
+			require.Equal(t, tc.islands, islands)
+			if len(tc.islands) == 1 {
+				require.NoError(t, g.Connected())
+			} else {
+				require.Error(t, g.Connected())
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package topology
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/spacemeshos/go-spacemesh/systest/cluster"
+	"github.com/spacemeshos/go-spacemesh/systest/testcontext"
+)
+
+const (
+	smesherContainer = "smesher"
+	// tcpEstablished is a state of the established connection in /proc/net/tcp.
+	tcpEstablished = "01"
+)
+
+// TCPSource returns peers based on the established tcp connections that use p2p port,
+// either as a local or as a remote port. Connections are read from /proc/net/tcp
+// inside the node container, and remote addresses are matched with nodes.
+//
+// It is used instead of the api because the api doesn't expose the list of peers.
+func TCPSource(cctx *testcontext.Context, nodes ...*cluster.NodeClient) Source {
+	ips := map[string]string{}
+	for _, node := range nodes {
+		ips[node.IP] = node.ID
+	}
+	return func(ctx context.Context, node *cluster.NodeClient) ([]string, error) {
+		out, err := execute(ctx, cctx, node.Name, smesherContainer, "cat", "/proc/net/tcp", "/proc/net/tcp6")
+		if err != nil {
+			return nil, err
+		}
+		conns, err := parseConnections(out)
+		if err != nil {
+			return nil, err
+		}
+		unique := map[string]struct{}{}
+		var rst []string
+		for _, conn := range conns {
+			if conn.localPort != int(node.P2P) && conn.remotePort != int(node.P2P) {
+				continue
+			}
+			id, exist := ips[conn.remoteIP.String()]
+			if !exist {
+				continue
+			}
+			if _, exist := unique[id]; !exist {
+				unique[id] = struct{}{}
+				rst = append(rst, id)
+			}
+		}
+		return rst, nil
+	}
+}
+
+// execute runs cmd in the pod container and returns stdout.
+// Executor in the client-go version that is used doesn't accept context, therefore
+// stream is abandoned if ctx is canceled before command completes.
+func execute(ctx context.Context, cctx *testcontext.Context, pod, container string, cmd ...string) ([]byte, error) {
+	req := cctx.Client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(cctx.Namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: container,
+			Command:   cmd,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(cctx.Config, "POST", req.URL())
+	if err != nil {
+		return nil, fmt.Errorf("exec in %s: %w", pod, err)
+	}
+	var stdout, stderr bytes.Buffer
+	errc := make(chan error, 1)
+	go func() {
+		errc <- executor.Stream(remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr})
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("exec %v in %s: %w", cmd, pod, ctx.Err())
+	case err := <-errc:
+		if err != nil {
+			return nil, fmt.Errorf("exec %v in %s: %w: %s", cmd, pod, err, stderr.String())
+		}
+	}
+	return stdout.Bytes(), nil
+}
+
+type connection struct {
+	localIP, remoteIP     net.IP
+	localPort, remotePort int
+}
+
+// parseConnections parses established connections in /proc/net/tcp{,6} format.
+func parseConnections(data []byte) ([]connection, error) {
+	var rst []connection
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// sl local_address rem_address st ...
+		if len(fields) < 4 || fields[0] == "sl" || fields[3] != tcpEstablished {
+			continue
+		}
+		localIP, localPort, err := parseAddress(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		remoteIP, remotePort, err := parseAddress(fields[2])
+		if err != nil {
+			return nil, err
+		}
+		rst = append(rst, connection{
+			localIP:    localIP,
+			localPort:  localPort,
+			remoteIP:   remoteIP,
+			remotePort: remotePort,
+		})
+	}
+	return rst, scanner.Err()
+}
+
+// parseAddress parses hex encoded address. Ip is stored as a sequence
+// of 32-bit words in host byte order (little endian), port is big endian.
+func parseAddress(raw string) (net.IP, int, error) {
+	parts := strings.Split(raw, ":")
+	if len(parts) != 2 {
+		return nil, 0, fmt.Errorf("invalid address %s", raw)
+	}
+	encoded, err := hex.DecodeString(parts[0])
+	if err != nil || (len(encoded) != net.IPv4len && len(encoded) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("invalid ip in %s", raw)
+	}
+	for i := 0; i < len(encoded); i += 4 {
+		word := encoded[i : i+4]
+		word[0], word[1], word[2], word[3] = word[3], word[2], word[1], word[0]
+	}
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid port in %s: %w", raw, err)
+	}
+	ip := net.IP(encoded)
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	return ip, int(port), nil
+}
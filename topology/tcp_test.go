@@ -0,0 +1,128 @@
+package topology
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAddress(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		raw  string
+		ip   net.IP
+		port int
+		err  bool
+	}{
+		{desc: "ipv4", raw: "0A00F40A:1B5E", ip: net.ParseIP("10.244.0.10"), port: 7006},
+		{desc: "loopback", raw: "0100007F:0277", ip: net.ParseIP("127.0.0.1"), port: 631},
+		{desc: "any", raw: "00000000:0000", ip: net.ParseIP("0.0.0.0"), port: 0},
+		{
+			desc: "ipv4 mapped",
+			raw:  "0000000000000000FFFF00000A00F40A:1B5E",
+			ip:   net.ParseIP("10.244.0.10"),
+			port: 7006,
+		},
+		{
+			desc: "ipv6",
+			raw:  "B80D01200000000067452301EFCDAB89:0016",
+			ip:   net.ParseIP("2001:db8::123:4567:89ab:cdef"),
+			port: 22,
+		},
+		{desc: "no port", raw: "0A00F40A", err: true},
+		{desc: "invalid hex", raw: "0A00F40Z:1B5E", err: true},
+		{desc: "invalid length", raw: "0A00F40A0A00F40A:1B5E", err: true},
+		{desc: "invalid port", raw: "0A00F40A:1B5EF", err: true},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			ip, port, err := parseAddress(tc.raw)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, tc.ip.Equal(ip), "expected %s, got %s", tc.ip, ip)
+			require.Equal(t, tc.port, port)
+		})
+	}
+}
+
+const (
+	procTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1B5E 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 20371 1 0000000000000000 100 0 0 10 0
+   1: 0A00F40A:1B5E 0B00F40A:C5A2 01 00000000:00000000 02:000A8F4A 00000000     0        0 20902 2 0000000000000000 20 4 29 10 -1
+   2: 0A00F40A:9F3C 0D00F40A:1B5E 01 00000000:00000000 02:000A8F4A 00000000     0        0 20915 2 0000000000000000 20 4 30 10 -1
+   3: 0A00F40A:A4B2 0E00F40A:1B5E 06 00000000:00000000 03:00001739 00000000     0        0 0 3 0000000000000000
+`
+	procTCP6 = `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:2382 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 20375 1 0000000000000000 100 0 0 10 0
+   1: 0000000000000000FFFF00000A00F40A:1B5E 0000000000000000FFFF00000C00F40A:D2F0 01 00000000:00000000 00:00000000 00000000     0        0 21044 1 0000000000000000 20 4 30 10 -1
+`
+)
+
+func TestParseConnections(t *testing.T) {
+	for _, tc := range []struct {
+		desc  string
+		data  string
+		conns []connection
+		err   bool
+	}{
+		{
+			desc: "tcp",
+			data: procTCP,
+			conns: []connection{
+				{
+					localIP: net.ParseIP("10.244.0.10"), localPort: 7006,
+					remoteIP: net.ParseIP("10.244.0.11"), remotePort: 50594,
+				},
+				{
+					localIP: net.ParseIP("10.244.0.10"), localPort: 40764,
+					remoteIP: net.ParseIP("10.244.0.13"), remotePort: 7006,
+				},
+			},
+		},
+		{
+			desc: "tcp and tcp6",
+			data: procTCP + procTCP6,
+			conns: []connection{
+				{
+					localIP: net.ParseIP("10.244.0.10"), localPort: 7006,
+					remoteIP: net.ParseIP("10.244.0.11"), remotePort: 50594,
+				},
+				{
+					localIP: net.ParseIP("10.244.0.10"), localPort: 40764,
+					remoteIP: net.ParseIP("10.244.0.13"), remotePort: 7006,
+				},
+				{
+					localIP: net.ParseIP("10.244.0.10"), localPort: 7006,
+					remoteIP: net.ParseIP("10.244.0.12"), remotePort: 54000,
+				},
+			},
+		},
+		{desc: "empty"},
+		{
+			desc: "invalid address",
+			data: "   1: 0A00F40A 0B00F40A:C5A2 01 00000000:00000000 02:000A8F4A 00000000     0        0 20902 2\n",
+			err:  true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			conns, err := parseConnections([]byte(tc.data))
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, conns, len(tc.conns))
+			for i, expected := range tc.conns {
+				require.True(t, expected.localIP.Equal(conns[i].localIP), "local ip %d: %s", i, conns[i].localIP)
+				require.True(t, expected.remoteIP.Equal(conns[i].remoteIP), "remote ip %d: %s", i, conns[i].remoteIP)
+				require.Equal(t, expected.localPort, conns[i].localPort)
+				require.Equal(t, expected.remotePort, conns[i].remotePort)
+			}
+		})
+	}
+}